@@ -3,24 +3,90 @@ alien-invasion CLI utility run scenario of the alien invasion on the given fanta
 
 USAGE:
 
-	alien-invasion [OPTIONS]
+	alien-invasion [COMMAND] [OPTIONS]
+
+COMMANDS:
+
+	run
+		Default. Runs scenario of the alien invasion and prints out resulting cities map
+	validate
+		Checks the map file and reports every problem found. Exits with non-zero code if the map is invalid
+	analyze
+		Analyses the map graph: connected components, dead ends and traps aliens can not leave, cycles,
+		degree and centrality of the cities, reachability and the shortest path between the given cities
+	batch
+		Runs many independent scenarios with seeds derived from the seed in parallel. Prints out per-city destruction
+		probability, distribution of the number of surviving cities and mean tick of the last destruction
 
 OPTIONS:
 
 	-f <PATH>
-		File path with the World Map definition
+		File path with the World Map definition. Required unless the scenario file gives the map path
 	-n <INT>
-		Number of aliens invading World
+		Number of aliens invading World. Required by the run and batch commands unless the scenario file defines aliens
+	-moves <INT>
+		Optional. Number of moves each alien can make. Default: 10000
+	-ticks <INT>
+		Optional. Total number of moves of all aliens which ends the invasion. Default: no limit
+	-strategy <SPEC>
+		Optional. Movement strategy of aliens: uniform, weighted:<DIRECTION>=<WEIGHT>,..., avoid-occupied,
+		seek-nearest, prefer-unvisited or stay-put:<PROBABILITY>. Default: uniform
+	-names <prefix:PREFIX|generate|PATH>
+		Optional. Names of aliens: prefix followed by the alien number, random names generated from the seed,
+		or names read from the file, one per line. Names given by the scenario file take precedence. Default: prefix:alien
+	-placement <unique|stack-fight|stack-peaceful|weighted>
+		Optional. Policy of the initial aliens placement. unique places aliens into distinct cities and limits
+		the number of aliens by the number of cities. Other policies allow several aliens in the same city:
+		stack-fight makes them fight right away, stack-peaceful lets them stay until another alien moves in,
+		weighted places aliens according to the city population and lets them stay. Default: unique
+	-population <PATH>
+		Optional. File with the population of the cities for the weighted placement, one "<CITY> <INT>" per line.
+		Cities missing in the file have population 1
 	-o <PATH>
 		Optional. Output file path. Default output: stdout
+	-runs <INT>
+		Optional. Number of scenarios run by the batch command. Default: 1000
+	-workers <INT>
+		Optional. Number of scenarios run by the batch command in parallel. Default: number of CPUs
+	-report <csv|txt|json>
+		Optional. Format of the batch (csv or json) and analyze (txt or json) commands report.
+		Default: detected by the output file extension, csv or txt otherwise
+	-from <CITY>
+		Optional. City the analyze command reports reachable cities for
+	-to <CITY>
+		Optional. City the analyze command reports the shortest path to from the -from city
+	-format <txt|txt-canonical|json|dot>
+		Optional. Map format. dot is available for the output only, txt-canonical writes the txt map sorted
+		by city name and direction for golden files. Default: detected by the file extension, txt otherwise
+	-symmetric
+		Optional. Infers missing reverse roads, e.g. Bar south=Foo for Foo north=Bar. Fails on conflicting roads
+	-simultaneous
+		Optional. Aliens move round by round all at once. Fights of 3 or more aliens are possible, aliens swapping cities destroy the road between them
+	-directions <compass4|compass8|layered|PATH>
+		Optional. Set of road directions, built-in or read from the file. Default: compass4
+	-events <PATH>
+		Optional. File path for the JSON Lines log of every scenario event. Supported by the run command
+	-dot <PATH>
+		Optional. File path for the Graphviz graphs of the map before and after the invasion. Supported by the run command
+	-stats <txt|json>
+		Optional. Prints summary of the invasion to stderr: destroyed cities, killed, trapped and exhausted aliens,
+		moves, tick of the last destruction, the largest surviving group of connected cities and visits of every city.
+		Supported by the run command
+	-scenario <PATH>
+		Optional. Scenario file (txt or json) with the map path, seed, moves, strategy and aliens with their names,
+		start cities, strategies and moves. Options given explicitly take precedence. Supported by the run and batch commands
+	-seed <INT>
+		Optional. Seed of the random source. Default: generated from the current time
 	-h
 		{{.Reset}}Print help information
 */
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"github.com/zippunov/alien-invasion/internal/encoding"
 	infrastructure2 "github.com/zippunov/alien-invasion/internal/infrastructure"
 	"github.com/zippunov/alien-invasion/internal/usecases"
 	"html/template"
@@ -33,15 +99,78 @@ Runs scenario of the alien invasion on the given fantasy map. Prints out resulti
 `
 
 var usage = `{{.Yellow}}USAGE:
-	{{.Reset}}alien-invasion [OPTIONS]
+	{{.Reset}}alien-invasion [COMMAND] [OPTIONS]
+
+{{.Yellow}}COMMANDS:
+	{{.Green}}run
+		{{.Reset}}Default. Runs scenario of the alien invasion and prints out resulting cities map
+	{{.Green}}validate
+		{{.Reset}}Checks the map file and reports every problem found. Exits with non-zero code if the map is invalid
+	{{.Green}}analyze
+		{{.Reset}}Analyses the map graph: connected components, dead ends and traps aliens can not leave, cycles,
+		degree and centrality of the cities, reachability and the shortest path between the given cities
+	{{.Green}}batch
+		{{.Reset}}Runs many independent scenarios with seeds derived from the seed in parallel. Prints out per-city destruction
+		probability, distribution of the number of surviving cities and mean tick of the last destruction
 
 {{.Yellow}}OPTIONS:
 	{{.Green}}-f <PATH>
-		{{.Reset}}File path with the World Map definition
+		{{.Reset}}File path with the World Map definition. Required unless the scenario file gives the map path
 	{{.Green}}-n <INT>
-		{{.Reset}}Number of aliens invading World
+		{{.Reset}}Number of aliens invading World. Required by the run and batch commands unless the scenario file defines aliens
+	{{.Green}}-moves <INT>
+		{{.Reset}}Optional. Number of moves each alien can make. Default: 10000
+	{{.Green}}-ticks <INT>
+		{{.Reset}}Optional. Total number of moves of all aliens which ends the invasion. Default: no limit
+	{{.Green}}-strategy <SPEC>
+		{{.Reset}}Optional. Movement strategy of aliens: uniform, weighted:<DIRECTION>=<WEIGHT>,..., avoid-occupied,
+		seek-nearest, prefer-unvisited or stay-put:<PROBABILITY>. Default: uniform
+	{{.Green}}-names <prefix:PREFIX|generate|PATH>
+		{{.Reset}}Optional. Names of aliens: prefix followed by the alien number, random names generated from the seed,
+		or names read from the file, one per line. Names given by the scenario file take precedence. Default: prefix:alien
+	{{.Green}}-placement <unique|stack-fight|stack-peaceful|weighted>
+		{{.Reset}}Optional. Policy of the initial aliens placement. unique places aliens into distinct cities and limits
+		the number of aliens by the number of cities. Other policies allow several aliens in the same city:
+		stack-fight makes them fight right away, stack-peaceful lets them stay until another alien moves in,
+		weighted places aliens according to the city population and lets them stay. Default: unique
+	{{.Green}}-population <PATH>
+		{{.Reset}}Optional. File with the population of the cities for the weighted placement, one "<CITY> <INT>" per line.
+		Cities missing in the file have population 1
 	{{.Green}}-o <PATH>
 		{{.Reset}}Optional. Output file path. Default output: stdout
+	{{.Green}}-runs <INT>
+		{{.Reset}}Optional. Number of scenarios run by the batch command. Default: 1000
+	{{.Green}}-workers <INT>
+		{{.Reset}}Optional. Number of scenarios run by the batch command in parallel. Default: number of CPUs
+	{{.Green}}-report <csv|txt|json>
+		{{.Reset}}Optional. Format of the batch (csv or json) and analyze (txt or json) commands report.
+		Default: detected by the output file extension, csv or txt otherwise
+	{{.Green}}-from <CITY>
+		{{.Reset}}Optional. City the analyze command reports reachable cities for
+	{{.Green}}-to <CITY>
+		{{.Reset}}Optional. City the analyze command reports the shortest path to from the -from city
+	{{.Green}}-format <txt|txt-canonical|json|dot>
+		{{.Reset}}Optional. Map format. dot is available for the output only, txt-canonical writes the txt map sorted
+		by city name and direction for golden files. Default: detected by the file extension, txt otherwise
+	{{.Green}}-symmetric
+		{{.Reset}}Optional. Infers missing reverse roads, e.g. Bar south=Foo for Foo north=Bar. Fails on conflicting roads
+	{{.Green}}-simultaneous
+		{{.Reset}}Optional. Aliens move round by round all at once. Fights of 3 or more aliens are possible, aliens swapping cities destroy the road between them
+	{{.Green}}-directions <compass4|compass8|layered|PATH>
+		{{.Reset}}Optional. Set of road directions, built-in or read from the file. Default: compass4
+	{{.Green}}-events <PATH>
+		{{.Reset}}Optional. File path for the JSON Lines log of every scenario event. Supported by the run command
+	{{.Green}}-dot <PATH>
+		{{.Reset}}Optional. File path for the Graphviz graphs of the map before and after the invasion. Supported by the run command
+	{{.Green}}-stats <txt|json>
+		{{.Reset}}Optional. Prints summary of the invasion to stderr: destroyed cities, killed, trapped and exhausted aliens,
+		moves, tick of the last destruction, the largest surviving group of connected cities and visits of every city.
+		Supported by the run command
+	{{.Green}}-scenario <PATH>
+		{{.Reset}}Optional. Scenario file (txt or json) with the map path, seed, moves, strategy and aliens with their names,
+		start cities, strategies and moves. Options given explicitly take precedence. Supported by the run and batch commands
+	{{.Green}}-seed <INT>
+		{{.Reset}}Optional. Seed of the random source. Default: generated from the current time
 	{{.Green}}-h
 		{{.Reset}}Print help information
 `
@@ -83,14 +212,83 @@ func main() {
 		handleError(err)
 	}
 	defer infra.Shutdown()
-	scenario, err := usecases.InitScenario(&infra)
+	switch config.Command {
+	case infrastructure2.CommandValidate:
+		validate(&infra)
+	case infrastructure2.CommandBatch:
+		batch(config, &infra)
+	case infrastructure2.CommandAnalyze:
+		analyze(&infra)
+	default:
+		run(config, &infra)
+	}
+}
+
+// run executes the Alien Invasion scenario
+func run(config infrastructure2.Config, infra *infrastructure2.Infra) {
+	log("seed: %d\n", config.Seed())
+	scenario, err := usecases.InitScenario(infra)
 	if err != nil {
+		infra.Shutdown()
 		handleError(err)
 	}
 	if err := scenario.Run(); err != nil {
+		infra.Shutdown()
+		handleError(err)
+	}
+	if err := infra.WriteStats(scenario.Stats()); err != nil {
+		infra.Shutdown()
+		handleError(err)
+	}
+}
+
+// batch executes many Alien Invasion scenarios and writes their aggregated outcomes
+func batch(config infrastructure2.Config, infra *infrastructure2.Infra) {
+	log("seed: %d\n", config.Seed())
+	b, err := usecases.InitBatch(infra)
+	if err != nil {
+		infra.Shutdown()
+		handleError(err)
+	}
+	report, err := b.Run()
+	if err != nil {
+		infra.Shutdown()
 		handleError(err)
 	}
-	os.Exit(0)
+	if err := infra.WriteReport(report); err != nil {
+		infra.Shutdown()
+		handleError(err)
+	}
+}
+
+// analyze executes the map graph analysis and writes its report
+func analyze(infra *infrastructure2.Infra) {
+	analysis := usecases.InitAnalysis(infra)
+	report, err := analysis.Run()
+	if err != nil {
+		infra.Shutdown()
+		handleError(err)
+	}
+	if err := infra.WriteAnalysis(report); err != nil {
+		infra.Shutdown()
+		handleError(err)
+	}
+}
+
+// validate executes the map validation. Exits with code 1 if the map is invalid.
+func validate(infra *infrastructure2.Infra) {
+	validation := usecases.InitValidation(infra)
+	err := validation.Run()
+	if err == nil {
+		return
+	}
+	infra.Shutdown()
+	var problems encoding.Problems
+	if !errors.As(err, &problems) {
+		handleError(err)
+	}
+	log("map is invalid: %d problem(s) found\n", len(problems))
+	os.Exit(1)
 }
 
 // configFlag redefines Usage() behavior of the flag package
@@ -8,10 +8,58 @@ USAGE:
 	OPTIONS:
 		-n <INT>
 			Number of cities
+		-names <letters|sequential[:PREFIX]|syllable|PATH>
+			Optional. Names of cities: single letters (up to 26 cities), prefix followed by the city number,
+			pronounceable fantasy names, or words drawn from the word list file. Default: letters
+		-no-digits
+			Optional. Names must not contain digits, sequential names are numbered with letters
+		-topology <random|grid|torus|hex|ring|line|erdos-renyi|barabasi-albert|watts-strogatz|strongly-connected>
+			Optional. Layout of cities. random links cities to random other cities in random directions. Geometric
+			topologies lay cities row by row and link neighbours: grid and torus north, south, east and west,
+			hex east, west and diagonally (requires compass8), ring and line east and west. Random graph models
+			link cities in random free directions: erdos-renyi links every pair of cities with probability -p,
+			barabasi-albert links every new city to -m cities preferring cities with many roads, watts-strogatz
+			links every city to -k nearest neighbours on the ring rewiring links with probability -beta,
+			strongly-connected links cities into the random cycle and adds -m random links of every city. Default: random
+		-width <INT>
+			Optional. Number of cities in the row of grid, torus and hex. Default: square layout
+		-one-way
+			Optional. Geometric topologies and random graph models link cities by a single road instead of two opposite roads
+		-remove <PROBABILITY>
+			Optional. Probability of the removal of the link between neighbours of geometric topologies. Default: 0
+		-p <PROBABILITY>
+			Optional. Link probability of the erdos-renyi model. Default: 0.001
+		-m <INT>
+			Optional. Links of every new city of the barabasi-albert model, extra links of every city of the
+			strongly-connected model. Default: 2 for barabasi-albert, 1 for strongly-connected
+		-k <INT>
+			Optional. Even number of nearest neighbours of the watts-strogatz model. Default: 2
+		-beta <PROBABILITY>
+			Optional. Rewiring probability of the watts-strogatz model. Default: 0.1
 		-o <PATH>
 			Optional. Output file path. Default output: stdout
+		-format <txt|txt-canonical|json|dot>
+			Optional. Map format, txt-canonical writes the txt map sorted by city name and direction.
+			Default: detected by the output file extension, txt otherwise
+		-directions <compass4|compass8|layered|PATH>
+			Optional. Set of road directions, built-in or read from the file. Default: compass4
+		-seed <INT>
+			Optional. Seed of the random source. Default: generated from the current time, printed to stderr
+		-manifest <header|PATH>
+			Optional. Records the generator version, topology, parameters and seed required to reproduce the map,
+			either as the comment line at the top of the txt map, or as the sidecar JSON file with the given path
+		-replay <PATH>
+			Optional. Regenerates the map from the manifest: the sidecar JSON file or the txt map with the manifest header.
+			Options of the map generation are ignored. The txt map must not be changed after the generation,
+			its copy keeps the manifest header unless -manifest is given
+		-verify
+			Optional. Round-trips the generated map through the map text loader before writing it,
+			the map failing any rule of the loader is not written
 		-h
 			Print help information
+
+	EXIT STATUS:
+		0 the map is written, 1 the map can not be written, 2 invalid options, 3 the generated map fails verification
 */
 package main
 
@@ -21,13 +69,14 @@ import (
 	"fmt"
 	"github.com/zippunov/alien-invasion/internal/domain"
 	"github.com/zippunov/alien-invasion/internal/encoding"
-	"math/rand"
+	"github.com/zippunov/alien-invasion/internal/mapgen"
+	"io"
 	"os"
+	"time"
 
 	"html/template"
 )
 
-var letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 var helpStr = `{{.Reset}}mapgen
 Builds random map for alien-invasion.
 
@@ -38,10 +87,58 @@ var usageStr = `{{.Yellow}}USAGE:
 {{.Yellow}}OPTIONS:
 	{{.Green}}-n <INT>
 		{{.Reset}}Number of cities
+	{{.Green}}-names <letters|sequential[:PREFIX]|syllable|PATH>
+		{{.Reset}}Optional. Names of cities: single letters (up to 26 cities), prefix followed by the city number,
+		pronounceable fantasy names, or words drawn from the word list file. Default: letters
+	{{.Green}}-no-digits
+		{{.Reset}}Optional. Names must not contain digits, sequential names are numbered with letters
+	{{.Green}}-topology <random|grid|torus|hex|ring|line|erdos-renyi|barabasi-albert|watts-strogatz|strongly-connected>
+		{{.Reset}}Optional. Layout of cities. random links cities to random other cities in random directions. Geometric
+		topologies lay cities row by row and link neighbours: grid and torus north, south, east and west,
+		hex east, west and diagonally (requires compass8), ring and line east and west. Random graph models
+		link cities in random free directions: erdos-renyi links every pair of cities with probability -p,
+		barabasi-albert links every new city to -m cities preferring cities with many roads, watts-strogatz
+		links every city to -k nearest neighbours on the ring rewiring links with probability -beta,
+		strongly-connected links cities into the random cycle and adds -m random links of every city. Default: random
+	{{.Green}}-width <INT>
+		{{.Reset}}Optional. Number of cities in the row of grid, torus and hex. Default: square layout
+	{{.Green}}-one-way
+		{{.Reset}}Optional. Geometric topologies and random graph models link cities by a single road instead of two opposite roads
+	{{.Green}}-remove <PROBABILITY>
+		{{.Reset}}Optional. Probability of the removal of the link between neighbours of geometric topologies. Default: 0
+	{{.Green}}-p <PROBABILITY>
+		{{.Reset}}Optional. Link probability of the erdos-renyi model. Default: 0.001
+	{{.Green}}-m <INT>
+		{{.Reset}}Optional. Links of every new city of the barabasi-albert model, extra links of every city of the
+		strongly-connected model. Default: 2 for barabasi-albert, 1 for strongly-connected
+	{{.Green}}-k <INT>
+		{{.Reset}}Optional. Even number of nearest neighbours of the watts-strogatz model. Default: 2
+	{{.Green}}-beta <PROBABILITY>
+		{{.Reset}}Optional. Rewiring probability of the watts-strogatz model. Default: 0.1
 	{{.Green}}-o <PATH>
 		{{.Reset}}Optional. Output file path. Default output: stdout
+	{{.Green}}-format <txt|txt-canonical|json|dot>
+		{{.Reset}}Optional. Map format, txt-canonical writes the txt map sorted by city name and direction.
+		Default: detected by the output file extension, txt otherwise
+	{{.Green}}-directions <compass4|compass8|layered|PATH>
+		{{.Reset}}Optional. Set of road directions, built-in or read from the file. Default: compass4
+	{{.Green}}-seed <INT>
+		{{.Reset}}Optional. Seed of the random source. Default: generated from the current time, printed to stderr
+	{{.Green}}-manifest <header|PATH>
+		{{.Reset}}Optional. Records the generator version, topology, parameters and seed required to reproduce the map,
+		either as the comment line at the top of the txt map, or as the sidecar JSON file with the given path
+	{{.Green}}-replay <PATH>
+		{{.Reset}}Optional. Regenerates the map from the manifest: the sidecar JSON file or the txt map with the manifest header.
+		Options of the map generation are ignored. The txt map must not be changed after the generation,
+		its copy keeps the manifest header unless -manifest is given
+	{{.Green}}-verify
+		{{.Reset}}Optional. Round-trips the generated map through the map text loader before writing it,
+		the map failing any rule of the loader is not written
 	{{.Green}}-h
 		{{.Reset}}Print help information
+
+{{.Yellow}}EXIT STATUS:
+	{{.Reset}}0 the map is written, 1 the map can not be written, 2 invalid options, 3 the generated map fails verification
 `
 var usageTemplate = template.Must(template.New("").Parse(usageStr))
 var helpTemplate = template.Must(template.New("").Parse(helpStr + usageStr))
@@ -60,17 +157,38 @@ var log = func(format string, a ...any) {
 
 func main() {
 	var (
-		citiesCount int
-		outFilePath string
-		help        bool
-		outFile     *os.File
-		err         error
+		mf           mapgen.Manifest
+		links        int
+		outFilePath  string
+		formatName   string
+		manifestPath string
+		replayPath   string
+		verify       bool
+		format       encoding.Format
+		help         bool
+		err          error
 	)
 	flag.Usage = func() {
 		_ = usageTemplate.Execute(os.Stderr, colors)
 	}
-	flag.IntVar(&citiesCount, "n", 0, "")
+	flag.IntVar(&mf.Cities, "n", 0, "")
+	flag.StringVar(&mf.Names, "names", "letters", "")
+	flag.BoolVar(&mf.NoDigits, "no-digits", false, "")
+	flag.StringVar(&mf.Topology, "topology", "random", "")
+	flag.IntVar(&mf.Width, "width", 0, "")
+	flag.BoolVar(&mf.OneWay, "one-way", false, "")
+	flag.Float64Var(&mf.Remove, "remove", 0, "")
+	flag.Float64Var(&mf.P, "p", 0.001, "")
+	flag.IntVar(&links, "m", -1, "")
+	flag.IntVar(&mf.K, "k", 2, "")
+	flag.Float64Var(&mf.Beta, "beta", 0.1, "")
+	flag.StringVar(&mf.Directions, "directions", "compass4", "")
+	flag.Int64Var(&mf.Seed, "seed", 0, "")
 	flag.StringVar(&outFilePath, "o", "", "")
+	flag.StringVar(&formatName, "format", "", "")
+	flag.StringVar(&manifestPath, "manifest", "", "")
+	flag.StringVar(&replayPath, "replay", "", "")
+	flag.BoolVar(&verify, "verify", false, "")
 	flag.BoolVar(&help, "h", false, "")
 	flag.Parse()
 
@@ -79,74 +197,186 @@ func main() {
 		os.Exit(0)
 	}
 
-	if citiesCount == 0 {
-		handleError(errors.New("missing number of cities"))
+	if len(formatName) != 0 {
+		var ok bool
+		if format, ok = encoding.FormatByName(formatName); !ok {
+			handleError(fmt.Errorf("unknown map format %s", formatName))
+		}
+	} else {
+		format, _ = encoding.FormatByPath(outFilePath)
+	}
+	replayHeader := false
+	if len(replayPath) != 0 {
+		if mf, replayHeader, err = readManifest(replayPath); err != nil {
+			handleError(err)
+		}
+		// the replayed txt map keeps its header, so the copy is the same file
+		if replayHeader && len(manifestPath) == 0 && isText(format) {
+			manifestPath = "header"
+		}
+	} else {
+		mf = manifestOf(mf, links)
+	}
+	log("seed: %d\n", mf.Seed)
+	if manifestPath == "header" && !isText(format) {
+		handleError(errors.New("manifest header requires txt map format"))
 	}
-	if citiesCount > len(letters) {
-		handleError(fmt.Errorf("max number of cities is %d", len(letters)))
+	directions, err := encoding.LoadDirections(mf.Directions)
+	if err != nil {
+		handleError(err)
 	}
+	domain.UseDirections(directions)
 
-	if len(outFilePath) != 0 {
-		if outFile, err = os.Create(outFilePath); err != nil {
+	m, err := mapgen.Generate(mf)
+	if err != nil {
+		handleError(err)
+	}
+	if replayHeader {
+		if err := checkUnchanged(replayPath, m); err != nil {
 			handleError(err)
 		}
 	}
-	if outFile == nil {
-		outFile = os.Stdout
+	if verify {
+		if err := mapgen.Verify(m); err != nil {
+			fail(fmt.Errorf("generated map fails verification:\n%v", err), exitInvalid)
+		}
+	}
+
+	if len(manifestPath) != 0 && manifestPath != "header" {
+		if err := writeManifest(manifestPath, mf); err != nil {
+			fail(err, exitFailure)
+		}
 	}
-	defer outFile.Close()
+	if err := writeMap(outFilePath, m, format, manifestPath == "header", mf); err != nil {
+		fail(err, exitFailure)
+	}
+}
 
-	m := generateMap(citiesCount)
-	encoding.MarshalTxt(outFile, m)
+// writeMap writes the Map into the file with the given path, stdout if the path is empty.
+// The Manifest is written as the header of the txt map if requested.
+func writeMap(path string, m domain.Map, format encoding.Format, header bool, mf mapgen.Manifest) error {
+	if len(path) == 0 {
+		return marshalMap(os.Stdout, m, format, header, mf)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := marshalMap(f, m, format, header, mf); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
 }
 
-func generateMap(count int) domain.Map {
-	m := domain.Map{}
-	b := []byte(letters)
-	rand.Shuffle(len(b), func(i, j int) {
-		b[i], b[j] = b[j], b[i]
-	})
-	b = b[:count]
-	for i := 0; i < len(b); i++ {
-		name := string(b[i])
-		otherCities := otherCitiesRandom(b, b[i])
-		dirs := randomDirections()
-		for i := 0; i < len(dirs) && i < len(otherCities); i++ {
-			linkedName := string(otherCities[i])
-			m.LinkCities(name, linkedName, dirs[i])
+// marshalMap writes the Map in the given format preceded by the Manifest header if requested
+func marshalMap(w io.Writer, m domain.Map, format encoding.Format, header bool, mf mapgen.Manifest) error {
+	if header {
+		if _, err := fmt.Fprintln(w, mf.Header()); err != nil {
+			return err
 		}
 	}
-	return m
+	return encoding.Marshal(w, m, format, nil)
 }
 
-func otherCitiesRandom(b []byte, city byte) []byte {
-	result := make([]byte, 0, len(b)-1)
-	for _, c := range b {
-		if c != city {
-			result = append(result, c)
+// manifestOf completes the Manifest given by the options: the seed is generated unless given,
+// parameters unused by the topology are dropped, so the Manifest records only the relevant ones.
+func manifestOf(mf mapgen.Manifest, links int) mapgen.Manifest {
+	mf.Version = mapgen.Version
+	if !isFlagSet("seed") {
+		mf.Seed = time.Now().UnixNano()
+	}
+	t, _ := mapgen.TopologyByName(mf.Topology)
+	if t != mapgen.TopologyBarabasiAlbert && t != mapgen.TopologyStrong {
+		mf.M = 0
+	} else if mf.M = links; links < 0 {
+		mf.M = 2
+		if t == mapgen.TopologyStrong {
+			mf.M = 1
 		}
 	}
-	rand.Shuffle(len(result), func(i, j int) {
-		result[i], result[j] = result[j], result[i]
-	})
-	return result
+	if t != mapgen.TopologyErdosRenyi {
+		mf.P = 0
+	}
+	if t != mapgen.TopologyWattsStrogatz {
+		mf.K, mf.Beta = 0, 0
+	}
+	switch t {
+	case mapgen.TopologyGrid, mapgen.TopologyTorus, mapgen.TopologyHex, mapgen.TopologyRing, mapgen.TopologyLine:
+	case mapgen.TopologyRandom:
+		mf.Width, mf.OneWay, mf.Remove = 0, false, 0
+	default:
+		mf.Width, mf.Remove = 0, 0
+	}
+	return mf
+}
+
+// readManifest reads the Manifest from the sidecar JSON file or the header of the txt map.
+// Reports whether the Manifest was read from the header.
+func readManifest(path string) (mapgen.Manifest, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return mapgen.Manifest{}, false, err
+	}
+	defer f.Close()
+	return mapgen.ReadManifest(f)
+}
+
+// checkUnchanged ensures that the txt map with the manifest header still holds the regenerated Map
+func checkUnchanged(path string, m domain.Map) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := mapgen.Unchanged(f, m); err != nil {
+		return fmt.Errorf("%s can not be replayed: %v", path, err)
+	}
+	return nil
+}
+
+// isText reports whether the map format is the txt one, which can hold the manifest header
+func isText(format encoding.Format) bool {
+	return format == encoding.Txt || format == encoding.TxtCanonical
 }
 
-func randomDirections() []domain.Direction {
-	dirs := []domain.Direction{
-		domain.North,
-		domain.East,
-		domain.South,
-		domain.West,
+// writeManifest writes the Manifest into the sidecar JSON file
+func writeManifest(path string, mf mapgen.Manifest) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
 	}
-	rand.Shuffle(4, func(i, j int) {
-		dirs[i], dirs[j] = dirs[j], dirs[i]
+	defer f.Close()
+	return mf.WriteJSON(f)
+}
+
+// isFlagSet reports whether the flag with given name was explicitly passed to the application
+func isFlagSet(name string) bool {
+	found := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
 	})
-	return dirs[:rand.Intn(4)+1]
+	return found
 }
 
+// Exit codes of the failures
+const (
+	exitFailure = 1 // the map can not be written
+	exitUsage   = 2 // invalid options, the same code is used by the flag package
+	exitInvalid = 3 // the generated map fails verification
+)
+
+// handleError reports invalid options together with the usage
 func handleError(err error) {
 	log("%v\n\n", err)
 	flag.Usage()
-	os.Exit(1)
+	os.Exit(exitUsage)
+}
+
+// fail reports the failure of the map generation and exits with the given code
+func fail(err error, code int) {
+	log("%v\n", err)
+	os.Exit(code)
 }
@@ -9,3 +9,54 @@ type Alien int
 func (a Alien) String() string {
 	return fmt.Sprintf("%d", a)
 }
+
+// AlienRegistry maps Alien ids to their names. Aliens are numbered from 0 in order of their names,
+// every name is unique.
+type AlienRegistry struct {
+	names  []string
+	byName map[string]Alien
+}
+
+// NewAlienRegistry creates AlienRegistry naming Alien i with names[i]. Names must be unique and not empty.
+func NewAlienRegistry(names []string) (*AlienRegistry, error) {
+	r := &AlienRegistry{
+		names:  append([]string(nil), names...),
+		byName: make(map[string]Alien, len(names)),
+	}
+	for i, name := range names {
+		if len(name) == 0 {
+			return nil, fmt.Errorf("alien %d has empty name", i)
+		}
+		if other, ok := r.byName[name]; ok {
+			return nil, fmt.Errorf("aliens %d and %d have the same name %s", other, i, name)
+		}
+		r.byName[name] = Alien(i)
+	}
+	return r, nil
+}
+
+// Len returns number of the named Aliens
+func (r *AlienRegistry) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.names)
+}
+
+// Name returns name of the Alien. Aliens unknown to the registry are named by their ids.
+func (r *AlienRegistry) Name(a Alien) string {
+	if r == nil || a < 0 || int(a) >= len(r.names) {
+		return a.String()
+	}
+	return r.names[a]
+}
+
+// ByName fetches Alien by its name.
+// Returns (0, false) if given name not found
+func (r *AlienRegistry) ByName(name string) (Alien, bool) {
+	if r == nil {
+		return 0, false
+	}
+	a, ok := r.byName[name]
+	return a, ok
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "testing"
+
+func TestNewAlienRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		wantErr bool
+	}{
+		{name: "Named aliens", names: []string{"Zorg", "Kang", "Big Bob"}},
+		{name: "No aliens", names: nil},
+		{name: "Empty name", names: []string{"Zorg", ""}, wantErr: true},
+		{name: "Duplicate name", names: []string{"Zorg", "Kang", "Zorg"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewAlienRegistry(tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAlienRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if r.Len() != len(tt.names) {
+				t.Errorf("Len() = %d, want %d", r.Len(), len(tt.names))
+			}
+			for i, name := range tt.names {
+				if got := r.Name(Alien(i)); got != name {
+					t.Errorf("Name(%d) = %s, want %s", i, got, name)
+				}
+				if got, ok := r.ByName(name); !ok || got != Alien(i) {
+					t.Errorf("ByName(%s) = %d, %v, want %d", name, got, ok, i)
+				}
+			}
+		})
+	}
+}
+
+func TestAlienRegistry_NameUnknown(t *testing.T) {
+	r, _ := NewAlienRegistry([]string{"Zorg"})
+	if got := r.Name(3); got != "3" {
+		t.Errorf("Name(3) = %s, want 3", got)
+	}
+	var empty *AlienRegistry
+	if got := empty.Name(0); got != "0" {
+		t.Errorf("nil registry Name(0) = %s, want 0", got)
+	}
+	if _, ok := empty.ByName("Zorg"); ok {
+		t.Errorf("nil registry ByName() must fail")
+	}
+}
@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
 // City is node in the Map graph. It keeps information about all incoming and outcoming roads
 // as well as list of Aliens occupying this City
 type City struct {
@@ -7,6 +13,7 @@ type City struct {
 	OutRoad   map[Direction]*City
 	inInroads RoadSet
 	Aliens    []Alien
+	roads     []Direction // out-road directions in order of creation
 }
 
 // String is a part of a Stringer interface implementation.
@@ -15,11 +22,129 @@ func (c *City) String() string {
 	return c.Name
 }
 
-// Directions returns list of all directions where roads out of the City available
+// Directions returns list of all directions where roads out of the City available.
+// Directions are listed in order of roads creation, so the result is stable between calls.
+// Roads put into OutRoad directly follow sorted by their enumeration value.
 func (c *City) Directions() []Direction {
 	result := make([]Direction, 0, len(c.OutRoad))
+	for _, d := range c.roads {
+		if _, ok := c.OutRoad[d]; ok {
+			result = append(result, d)
+		}
+	}
+	if len(result) == len(c.OutRoad) {
+		return result
+	}
+	extra := make([]Direction, 0, len(c.OutRoad)-len(result))
 	for d := range c.OutRoad {
+		if !c.hasRoadOrder(d) {
+			extra = append(extra, d)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool {
+		return extra[i] < extra[j]
+	})
+	return append(result, extra...)
+}
+
+// hasRoadOrder reports whether the creation order of the out-road in given direction is known
+func (c *City) hasRoadOrder(d Direction) bool {
+	for _, rd := range c.roads {
+		if rd == d {
+			return true
+		}
+	}
+	return false
+}
+
+// addRoad registers out-road to the given City
+func (c *City) addRoad(d Direction, to *City) {
+	c.OutRoad[d] = to
+	if !c.hasRoadOrder(d) {
+		c.roads = append(c.roads, d)
+	}
+}
+
+// removeRoad deletes out-road in the given direction
+func (c *City) removeRoad(d Direction) {
+	delete(c.OutRoad, d)
+	for i, rd := range c.roads {
+		if rd == d {
+			c.roads = append(c.roads[:i:i], c.roads[i+1:]...)
+			return
+		}
+	}
+}
+
+// diff lists differences of the other City from this one. Cities are compared by names of the linked Cities.
+func (c *City) diff(other *City) []string {
+	var result []string
+	for _, d := range sortedDirections(c.OutRoad, other.OutRoad) {
+		want, got := c.OutRoad[d], other.OutRoad[d]
+		switch {
+		case got == nil:
+			result = append(result, fmt.Sprintf("city %s: missing road %v to %s", c.Name, d, want))
+		case want == nil:
+			result = append(result, fmt.Sprintf("city %s: extra road %v to %s", c.Name, d, got))
+		case want.Name != got.Name:
+			result = append(result, fmt.Sprintf("city %s: road %v to %s, got %s", c.Name, d, want, got))
+		}
+	}
+	wantIn, gotIn := c.inRoadNames(), other.inRoadNames()
+	for _, r := range wantIn {
+		if !containsString(gotIn, r) {
+			result = append(result, fmt.Sprintf("city %s: missing in-road %s", c.Name, r))
+		}
+	}
+	for _, r := range gotIn {
+		if !containsString(wantIn, r) {
+			result = append(result, fmt.Sprintf("city %s: extra in-road %s", c.Name, r))
+		}
+	}
+	if !reflect.DeepEqual(sortedAliens(c.Aliens), sortedAliens(other.Aliens)) {
+		result = append(result, fmt.Sprintf("city %s: aliens %v, got %v", c.Name, c.Aliens, other.Aliens))
+	}
+	return result
+}
+
+// inRoadNames lists in-roads of the City as "<CITY> <DIRECTION>" sorted
+func (c *City) inRoadNames() []string {
+	result := make([]string, 0, len(c.inInroads))
+	for r := range c.inInroads {
+		result = append(result, fmt.Sprintf("%s %v", r.from, r.direction))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// sortedDirections returns union of the directions of the given out-roads sorted by their enumeration value
+func sortedDirections(a, b map[Direction]*City) []Direction {
+	result := make([]Direction, 0, len(a)+len(b))
+	for d := range a {
 		result = append(result, d)
 	}
+	for d := range b {
+		if _, ok := a[d]; !ok {
+			result = append(result, d)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+	return result
+}
+
+// sortedAliens returns sorted copy of the Aliens list, nil and empty lists are equal
+func sortedAliens(aliens []Alien) []Alien {
+	result := append([]Alien{}, aliens...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
 	return result
 }
+
+// containsString reports whether sorted list contains given string
+func containsString(list []string, s string) bool {
+	i := sort.SearchStrings(list, s)
+	return i < len(list) && list[i] == s
+}
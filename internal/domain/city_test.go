@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -88,3 +89,18 @@ func TestCity_String(t *testing.T) {
 		})
 	}
 }
+
+func TestCity_DirectionsOrder(t *testing.T) {
+	m := Map{}
+	_ = m.LinkCities("A", "B", West)
+	_ = m.LinkCities("A", "C", North)
+	_ = m.LinkCities("A", "D", South)
+	_ = m.LinkCities("C", "A", East)
+	m.DestroyCity(m["C"])
+	_ = m.LinkCities("A", "E", North)
+	m["A"].OutRoad[East] = m["D"]
+	want := []Direction{West, South, North, East}
+	if got := m["A"].Directions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Directions() = %v, want %v", got, want)
+	}
+}
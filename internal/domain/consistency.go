@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InconsistencyKind is an integer value that identifies the kind of the road Inconsistency
+type InconsistencyKind uint
+
+// Enumeration of all Inconsistency kinds
+const (
+	// OneWayRoad is the road without the reverse road in the opposite direction
+	OneWayRoad InconsistencyKind = iota
+	// ConflictingRoad is the road whose opposite direction in the destination City leads to another City
+	ConflictingRoad
+)
+
+// Inconsistency describes the road which breaks Map symmetry. Road goes From the City in the Direction
+// To another City. Conflict holds name of the City the opposite road leads to for the ConflictingRoad.
+type Inconsistency struct {
+	Kind      InconsistencyKind
+	From      string
+	To        string
+	Direction Direction
+	Conflict  string
+}
+
+// String is a part of the Stringer interface implementation for the Inconsistency
+func (i Inconsistency) String() string {
+	if i.Kind == ConflictingRoad {
+		return fmt.Sprintf("road %v %v=%v conflicts with road %v %v=%v",
+			i.From, i.Direction, i.To, i.To, i.Direction.Opposite(), i.Conflict)
+	}
+	return fmt.Sprintf("road %v %v=%v has no reverse road %v %v=%v",
+		i.From, i.Direction, i.To, i.To, i.Direction.Opposite(), i.From)
+}
+
+// CheckConsistency reports every road of the Map without a consistent reverse road.
+// Map is symmetric if for each road Foo north=Bar there is road Bar south=Foo.
+// Inconsistencies are listed in order of Cities names and their roads directions.
+func (m *Map) CheckConsistency() []Inconsistency {
+	var result []Inconsistency
+	for _, city := range m.ListCities() {
+		for _, dir := range city.Directions() {
+			to := city.OutRoad[dir]
+			reverse, ok := to.OutRoad[dir.Opposite()]
+			switch {
+			case !ok:
+				result = append(result, Inconsistency{OneWayRoad, city.Name, to.Name, dir, ""})
+			case reverse != city:
+				result = append(result, Inconsistency{ConflictingRoad, city.Name, to.Name, dir, reverse.Name})
+			}
+		}
+	}
+	return result
+}
+
+// Symmetrize makes Map symmetric by creating every missing reverse road with LinkCities.
+// Map is not changed if there are conflicting roads, including one-way roads which require
+// the same reverse road to different Cities. All conflicts are reported with the error.
+func (m *Map) Symmetrize() error {
+	type slot struct {
+		city      string
+		direction Direction
+	}
+	var conflicts []string
+	required := map[slot]Inconsistency{}
+	inconsistencies := m.CheckConsistency()
+	for _, i := range inconsistencies {
+		if i.Kind == ConflictingRoad {
+			conflicts = append(conflicts, i.String())
+			continue
+		}
+		s := slot{i.To, i.Direction.Opposite()}
+		if other, ok := required[s]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("roads %v %v=%v and %v %v=%v require the same reverse road from %v",
+				other.From, other.Direction, other.To, i.From, i.Direction, i.To, i.To))
+			continue
+		}
+		required[s] = i
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("map can not be symmetric: %s", strings.Join(conflicts, "; "))
+	}
+	for _, i := range inconsistencies {
+		if err := m.LinkCities(i.To, i.From, i.Direction.Opposite()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
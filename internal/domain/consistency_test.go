@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMap_CheckConsistency(t *testing.T) {
+	symmetric := Map{}
+	_ = symmetric.LinkCities("A", "B", North)
+	_ = symmetric.LinkCities("B", "A", South)
+	oneWay := Map{}
+	_ = oneWay.LinkCities("A", "B", North)
+	_ = oneWay.LinkCities("B", "A", South)
+	_ = oneWay.LinkCities("A", "C", East)
+	conflict := Map{}
+	_ = conflict.LinkCities("A", "B", North)
+	_ = conflict.LinkCities("B", "C", South)
+	_ = conflict.LinkCities("C", "B", North)
+	tests := []struct {
+		name string
+		m    Map
+		want []Inconsistency
+	}{
+		{
+			name: "Empty map",
+			m:    Map{},
+			want: nil,
+		},
+		{
+			name: "Symmetric map",
+			m:    symmetric,
+			want: nil,
+		},
+		{
+			name: "One way road",
+			m:    oneWay,
+			want: []Inconsistency{
+				{Kind: OneWayRoad, From: "A", To: "C", Direction: East},
+			},
+		},
+		{
+			name: "Conflicting road",
+			m:    conflict,
+			want: []Inconsistency{
+				{Kind: ConflictingRoad, From: "A", To: "B", Direction: North, Conflict: "C"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.CheckConsistency(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckConsistency() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMap_Symmetrize(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(m Map)
+		wantErr bool
+	}{
+		{
+			name: "Half of the edges",
+			build: func(m Map) {
+				_ = m.LinkCities("A", "B", North)
+				_ = m.LinkCities("A", "C", East)
+				_ = m.LinkCities("B", "D", West)
+				_ = m.LinkCities("D", "B", East)
+			},
+		},
+		{
+			name: "Conflicting road",
+			build: func(m Map) {
+				_ = m.LinkCities("A", "B", North)
+				_ = m.LinkCities("B", "C", South)
+			},
+			wantErr: true,
+		},
+		{
+			name: "Same reverse road required twice",
+			build: func(m Map) {
+				_ = m.LinkCities("A", "B", North)
+				_ = m.LinkCities("C", "B", North)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Map{}
+			tt.build(m)
+			roads := 0
+			for _, c := range m {
+				roads += len(c.OutRoad)
+			}
+			err := m.Symmetrize()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Symmetrize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				after := 0
+				for _, c := range m {
+					after += len(c.OutRoad)
+				}
+				if after != roads {
+					t.Errorf("Symmetrize() changed map on error, roads %d, want %d", after, roads)
+				}
+				return
+			}
+			if got := m.CheckConsistency(); len(got) != 0 {
+				t.Errorf("Symmetrize() left inconsistencies %v", got)
+			}
+		})
+	}
+}
@@ -1,42 +1,234 @@
 package domain
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
 
-// Direction is an integer value that identifies on of for directions (North, East, South, West).
+// Direction is an integer value that identifies one of the directions of the active DirectionSet.
 type Direction uint
 
-// Enumeration of all available directions
+// Enumeration of all built-in directions. Custom directions of the user-supplied sets
+// are numbered starting from firstCustomDirection.
 const (
 	North Direction = iota
 	East
 	South
 	West
+	NorthEast
+	SouthEast
+	SouthWest
+	NorthWest
+	Up
+	Down
+	firstCustomDirection
+)
+
+// builtinNames links built-in Direction enumeration to their names
+var builtinNames = map[Direction]string{
+	North:     "north",
+	East:      "east",
+	South:     "south",
+	West:      "west",
+	NorthEast: "northeast",
+	SouthEast: "southeast",
+	SouthWest: "southwest",
+	NorthWest: "northwest",
+	Up:        "up",
+	Down:      "down",
+}
+
+// builtinOpposites links every built-in Direction to the opposite one
+var builtinOpposites = map[Direction]Direction{
+	North:     South,
+	East:      West,
+	South:     North,
+	West:      East,
+	NorthEast: SouthWest,
+	SouthEast: NorthWest,
+	SouthWest: NorthEast,
+	NorthWest: SouthEast,
+	Up:        Down,
+	Down:      Up,
+}
+
+// DirectionSet is the list of Directions available for the roads. Every Direction of the set
+// has a unique name and the opposite Direction within the set. Number of Directions in the set
+// limits number of out-roads of the City.
+type DirectionSet struct {
+	dirs      []Direction
+	names     map[Direction]string
+	byName    map[string]Direction
+	opposites map[Direction]Direction
+}
+
+// Built-in direction sets
+var (
+	// Compass4 is the default set of four compass directions
+	Compass4 = newBuiltinSet(North, East, South, West)
+	// Compass8 is the set of four compass directions and four intercardinal directions (northeast, etc.)
+	Compass8 = newBuiltinSet(North, East, South, West, NorthEast, SouthEast, SouthWest, NorthWest)
+	// Layered is the set of four compass directions with up and down directions for the multi-level maps
+	Layered = newBuiltinSet(North, East, South, West, Up, Down)
 )
 
-// dirMap links string names to the Direction enumeration
-var dirMap = map[string]Direction{
-	"north": North,
-	"east":  East,
-	"south": South,
-	"west":  West,
+// builtinSets links names to the built-in direction sets
+var builtinSets = map[string]*DirectionSet{
+	"compass4": Compass4,
+	"compass8": Compass8,
+	"layered":  Layered,
+}
+
+// activeSet is the DirectionSet used by the application. It is chosen once at the application start,
+// before it is read: Directions of the Map built with one set mean other roads in another set.
+var activeSet = Compass4
+
+// activeRead reports whether activeSet was read, after that the set can not be changed
+var activeRead atomic.Bool
+
+// newBuiltinSet creates DirectionSet from the built-in Directions
+func newBuiltinSet(dirs ...Direction) *DirectionSet {
+	s := &DirectionSet{
+		dirs:      dirs,
+		names:     make(map[Direction]string, len(dirs)),
+		byName:    make(map[string]Direction, len(dirs)),
+		opposites: make(map[Direction]Direction, len(dirs)),
+	}
+	for _, d := range dirs {
+		s.names[d] = builtinNames[d]
+		s.byName[builtinNames[d]] = d
+		s.opposites[d] = builtinOpposites[d]
+	}
+	return s
+}
+
+// NewDirectionSet creates DirectionSet from the list of names and their opposites.
+// opposites[i] is the name of the Direction opposite to names[i]. Names are case-insensitive.
+// Built-in names keep their built-in Direction values, other names get new values.
+func NewDirectionSet(names, opposites []string) (*DirectionSet, error) {
+	if len(names) == 0 {
+		return nil, errors.New("direction set is empty")
+	}
+	if len(names) != len(opposites) {
+		return nil, errors.New("every direction must have the opposite one")
+	}
+	s := &DirectionSet{
+		dirs:      make([]Direction, 0, len(names)),
+		names:     make(map[Direction]string, len(names)),
+		byName:    make(map[string]Direction, len(names)),
+		opposites: make(map[Direction]Direction, len(names)),
+	}
+	custom := firstCustomDirection
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if len(name) == 0 || strings.ContainsAny(name, "=#\"\\") || strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+			return nil, fmt.Errorf("invalid direction name \"%s\"", name)
+		}
+		if _, ok := s.byName[name]; ok {
+			return nil, fmt.Errorf("duplicate direction %s", name)
+		}
+		d, ok := Compass8.ByName(name)
+		if !ok {
+			d, ok = Layered.ByName(name)
+		}
+		if !ok {
+			d = custom
+			custom++
+		}
+		s.dirs = append(s.dirs, d)
+		s.names[d] = name
+		s.byName[name] = d
+	}
+	for i, name := range opposites {
+		d := s.dirs[i]
+		o, ok := s.byName[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown opposite direction %s for %s", name, s.names[d])
+		}
+		if o == d {
+			return nil, fmt.Errorf("direction %s can not be opposite to itself", s.names[d])
+		}
+		s.opposites[d] = o
+	}
+	for d, o := range s.opposites {
+		if s.opposites[o] != d {
+			return nil, fmt.Errorf("opposite of %s is %s, but opposite of %s is %s",
+				s.names[d], s.names[o], s.names[o], s.names[s.opposites[o]])
+		}
+	}
+	return s, nil
+}
+
+// DirectionSetByName fetches built-in DirectionSet by its name (compass4, compass8, layered).
+// Returns (nil, false) if given name not found
+func DirectionSetByName(name string) (*DirectionSet, bool) {
+	s, ok := builtinSets[strings.ToLower(name)]
+	return s, ok
 }
 
-// dirNames links Direction enumeration to their names
-var dirNames = map[Direction]string{
-	North: "north",
-	East:  "east",
-	South: "south",
-	West:  "west",
+// UseDirections makes given DirectionSet active. It must be called once at the application start,
+// before any Map is built or any Direction is named. Panics if the other set was already read.
+func UseDirections(s *DirectionSet) {
+	if s != activeSet && activeRead.Load() {
+		panic("domain: direction set is changed after it was used")
+	}
+	activeSet = s
 }
 
-// DirectionByName fetched Direction by its name.
+// resetDirections makes Compass4 active again and allows UseDirections to choose another set.
+// It is meant for the tests switching direction sets, the application never calls it.
+func resetDirections() {
+	activeRead.Store(false)
+	activeSet = Compass4
+}
+
+// ActiveDirections returns the DirectionSet used by the application
+func ActiveDirections() *DirectionSet {
+	return active()
+}
+
+// active returns activeSet and marks it as read
+func active() *DirectionSet {
+	if !activeRead.Load() {
+		activeRead.Store(true)
+	}
+	return activeSet
+}
+
+// Len returns number of Directions in the set, which is the limit of out-roads per City
+func (s *DirectionSet) Len() int {
+	return len(s.dirs)
+}
+
+// All returns list of all Directions of the set
+func (s *DirectionSet) All() []Direction {
+	return append([]Direction(nil), s.dirs...)
+}
+
+// ByName fetches Direction of the set by its name.
 // Returns (0, false) if given name not found
-func DirectionByName(name string) (Direction, bool) {
-	result, ok := dirMap[strings.ToLower(name)]
+func (s *DirectionSet) ByName(name string) (Direction, bool) {
+	result, ok := s.byName[strings.ToLower(name)]
 	return result, ok
 }
 
-// String is a part of the Stringer interface implementation for the Direction
+// DirectionByName fetches Direction of the active set by its name.
+// Returns (0, false) if given name not found
+func DirectionByName(name string) (Direction, bool) {
+	return active().ByName(name)
+}
+
+// String is a part of the Stringer interface implementation for the Direction.
+// Direction is named according to the active set.
 func (d Direction) String() string {
-	return dirNames[d]
+	return active().names[d]
+}
+
+// Opposite returns Direction of the reverse road within the active set. For example, road north
+// from Foo to Bar is consistent with the road south from Bar to Foo.
+func (d Direction) Opposite() Direction {
+	return active().opposites[d]
 }
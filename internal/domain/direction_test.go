@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestNewDirectionSet(t *testing.T) {
+	tests := []struct {
+		name      string
+		names     []string
+		opposites []string
+		wantLen   int
+		wantErr   bool
+	}{
+		{
+			name:      "Hex grid",
+			names:     []string{"northeast", "East", "southeast", "southwest", "west", "northwest"},
+			opposites: []string{"southwest", "west", "northwest", "northeast", "east", "southeast"},
+			wantLen:   6,
+		},
+		{
+			name:      "Custom names",
+			names:     []string{"in", "out", "north", "south"},
+			opposites: []string{"out", "in", "south", "north"},
+			wantLen:   4,
+		},
+		{
+			name:    "Empty set",
+			wantErr: true,
+		},
+		{
+			name:      "Missing opposite",
+			names:     []string{"in", "out"},
+			opposites: []string{"out"},
+			wantErr:   true,
+		},
+		{
+			name:      "Unknown opposite",
+			names:     []string{"in", "out"},
+			opposites: []string{"out", "up"},
+			wantErr:   true,
+		},
+		{
+			name:      "Opposite to itself",
+			names:     []string{"in", "out"},
+			opposites: []string{"in", "out"},
+			wantErr:   true,
+		},
+		{
+			name:      "Asymmetric opposites",
+			names:     []string{"a", "b", "c"},
+			opposites: []string{"b", "c", "a"},
+			wantErr:   true,
+		},
+		{
+			name:      "Duplicate name",
+			names:     []string{"in", "IN"},
+			opposites: []string{"in", "in"},
+			wantErr:   true,
+		},
+		{
+			name:      "Invalid name",
+			names:     []string{"a=b", "c"},
+			opposites: []string{"c", "a=b"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewDirectionSet(tt.names, tt.opposites)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewDirectionSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Len() != tt.wantLen {
+				t.Errorf("NewDirectionSet() Len() = %v, want %v", got.Len(), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestUseDirections(t *testing.T) {
+	defer ResetDirections()
+	custom, err := NewDirectionSet([]string{"in", "out", "north", "south"}, []string{"out", "in", "south", "north"})
+	if err != nil {
+		t.Fatalf("NewDirectionSet() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		set      *DirectionSet
+		dirName  string
+		wantOk   bool
+		opposite string
+	}{
+		{"Compass4 north", Compass4, "north", true, "south"},
+		{"Compass4 northeast", Compass4, "northeast", false, ""},
+		{"Compass8 northeast", Compass8, "NorthEast", true, "southwest"},
+		{"Layered up", Layered, "up", true, "down"},
+		{"Custom in", custom, "in", true, "out"},
+		{"Custom north keeps built-in value", custom, "north", true, "south"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetDirections()
+			UseDirections(tt.set)
+			d, ok := DirectionByName(tt.dirName)
+			if ok != tt.wantOk {
+				t.Fatalf("DirectionByName() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if got := d.Opposite().String(); got != tt.opposite {
+				t.Errorf("Opposite() = %v, want %v", got, tt.opposite)
+			}
+		})
+	}
+	ResetDirections()
+	UseDirections(custom)
+	if d, _ := DirectionByName("north"); d != North {
+		t.Errorf("DirectionByName() = %d, want %d", d, North)
+	}
+}
+
+func TestUseDirections_afterRead(t *testing.T) {
+	defer ResetDirections()
+	ResetDirections()
+	_ = North.String()
+	UseDirections(Compass4)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("UseDirections() changed the set after it was read")
+		}
+	}()
+	UseDirections(Compass8)
+}
@@ -0,0 +1,57 @@
+/*
+Package domaintest runs the tests of other packages with the direction sets other than Compass4.
+The active direction set can not be changed after it was read, so the test of another set runs
+in the child process of the test binary, which activates the set before any test starts.
+*/
+package domaintest
+
+import (
+	"errors"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// directionsEnv names the direction set of the child test process
+const directionsEnv = "ALIEN_INVASION_TEST_DIRECTIONS"
+
+// Main activates the direction set of the child test process and runs the tests.
+// It is called from TestMain of the packages using RunWithDirections.
+func Main(m *testing.M) {
+	if name := os.Getenv(directionsEnv); len(name) != 0 {
+		set, ok := domain.DirectionSetByName(name)
+		if !ok {
+			panic("domaintest: unknown direction set " + name)
+		}
+		domain.UseDirections(set)
+	}
+	os.Exit(m.Run())
+}
+
+// RunWithDirections reports whether the test runs with the built-in direction set of the given name active,
+// so the caller goes on with the test body. Otherwise the test is run again in the child process
+// with the set active, its failure fails the test, and the caller returns.
+func RunWithDirections(t *testing.T, name string) bool {
+	t.Helper()
+	active := os.Getenv(directionsEnv)
+	if active == name || len(active) == 0 && name == "compass4" {
+		return true
+	}
+	parts := strings.Split(t.Name(), "/")
+	for i, part := range parts {
+		parts[i] = "^" + regexp.QuoteMeta(part) + "$"
+	}
+	cmd := exec.Command(os.Args[0], "-test.v", "-test.run="+strings.Join(parts, "/"))
+	cmd.Env = append(os.Environ(), directionsEnv+"="+name)
+	out, err := cmd.CombinedOutput()
+	if err == nil && !strings.Contains(string(out), "--- PASS: "+t.Name()+" ") {
+		err = errors.New("test did not run")
+	}
+	if err != nil {
+		t.Errorf("%s with %s directions: %v\n%s", t.Name(), name, err, out)
+	}
+	return false
+}
@@ -0,0 +1,4 @@
+package domain
+
+// ResetDirections exposes resetDirections to the tests switching direction sets
+var ResetDirections = resetDirections
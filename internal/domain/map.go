@@ -29,7 +29,7 @@ func (m *Map) LinkCities(from, to string, direction Direction) error {
 		return err
 	}
 	sourceCity, targetCity := m.InitCity(from), m.InitCity(to)
-	sourceCity.OutRoad[direction] = targetCity
+	sourceCity.addRoad(direction, targetCity)
 	targetCity.inInroads.add(road{sourceCity, direction})
 	return nil
 }
@@ -54,14 +54,25 @@ func (m *Map) validateLink(from, to string, direction Direction) error {
 // DestroyCity removes City from the map. All ingoing and outgoing roads will be deleted in all linked cities.
 func (m *Map) DestroyCity(city *City) {
 	for dir, c := range city.OutRoad {
-		c.inInroads.remove(road{c, dir})
+		c.inInroads.remove(road{city, dir})
 	}
 	for r := range city.inInroads {
-		delete(r.from.OutRoad, r.direction)
+		r.from.removeRoad(r.direction)
 	}
 	delete(*m, city.Name)
 }
 
+// DestroyRoad removes out-road of the City in the given Direction. The road is removed from
+// the in-roads of the target City as well. Nothing happens if there is no road in the given Direction.
+func (m *Map) DestroyRoad(city *City, direction Direction) {
+	target, ok := city.OutRoad[direction]
+	if !ok {
+		return
+	}
+	target.inInroads.remove(road{city, direction})
+	city.removeRoad(direction)
+}
+
 // InitCity creates empty City instance with given name and indexes City in the Map
 func (m *Map) InitCity(name string) *City {
 	city, ok := (*m)[name]
@@ -76,3 +87,54 @@ func (m *Map) InitCity(name string) *City {
 	}
 	return (*m)[name]
 }
+
+// Clone returns deep copy of the Map. Every City is copied together with its Aliens and roads order,
+// roads of the copy link copied Cities only, so the copy can be changed independently of the original.
+func (m *Map) Clone() Map {
+	result := make(Map, len(*m))
+	for name, city := range *m {
+		result[name] = &City{
+			Name:      city.Name,
+			OutRoad:   make(map[Direction]*City, len(city.OutRoad)),
+			inInroads: make(RoadSet, len(city.inInroads)),
+			Aliens:    append([]Alien{}, city.Aliens...),
+			roads:     append([]Direction(nil), city.roads...),
+		}
+	}
+	for name, city := range *m {
+		clone := result[name]
+		for d, next := range city.OutRoad {
+			clone.OutRoad[d] = result[next.Name]
+		}
+		for r := range city.inInroads {
+			clone.inInroads.add(road{result[r.from.Name], r.direction})
+		}
+	}
+	return result
+}
+
+// Equal reports whether other Map has the same Cities, roads and Aliens
+func (m *Map) Equal(other Map) bool {
+	return len(m.Diff(other)) == 0
+}
+
+// Diff lists differences of the other Map from this one: missing and extra Cities, out-roads, in-roads
+// and Aliens of the Cities. Differences are sorted by City name, empty list means Maps are equal.
+// Order of the roads is not compared.
+func (m *Map) Diff(other Map) []string {
+	var result []string
+	for _, city := range m.ListCities() {
+		o, ok := other[city.Name]
+		if !ok {
+			result = append(result, fmt.Sprintf("city %s: missing", city.Name))
+			continue
+		}
+		result = append(result, city.diff(o)...)
+	}
+	for _, city := range other.ListCities() {
+		if _, ok := (*m)[city.Name]; !ok {
+			result = append(result, fmt.Sprintf("city %s: extra", city.Name))
+		}
+	}
+	return result
+}
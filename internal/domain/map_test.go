@@ -108,6 +108,46 @@ func TestMap_DestroyCity(t *testing.T) {
 	}
 }
 
+func TestMap_DestroyCity_inRoads(t *testing.T) {
+	m := buildMap1()
+	d := m["D"]
+	m.DestroyCity(d)
+	for _, city := range m {
+		for r := range city.inInroads {
+			if r.from == d {
+				t.Errorf("in-road %v %v of the destroyed city must be removed from %v", r.from, r.direction, city)
+			}
+		}
+	}
+	want := buildMap1()
+	want.DestroyCity(want["D"])
+	if diff := m.Diff(want); len(diff) != 0 {
+		t.Errorf("DestroyCity() diff = %v", diff)
+	}
+}
+
+func TestMap_DestroyRoad(t *testing.T) {
+	m := buildMap1()
+	c, j := m["C"], m["J"]
+	m.DestroyRoad(c, South)
+	if _, ok := c.OutRoad[South]; ok {
+		t.Errorf("road C south=J must be removed")
+	}
+	if _, ok := j.inInroads[road{c, South}]; ok {
+		t.Errorf("in-road C south=J must be removed from J")
+	}
+	if got := c.Directions(); !reflect.DeepEqual(got, []Direction{West, East, North}) {
+		t.Errorf("C directions = %v, want [west east north]", got)
+	}
+	if j.OutRoad[East] != c {
+		t.Errorf("reverse road J east=C must be kept")
+	}
+	m.DestroyRoad(c, South)
+	if len(m) != 6 {
+		t.Errorf("Length mismath, want %v, got %v", 6, len(m))
+	}
+}
+
 func TestMap_InitCity(t *testing.T) {
 	m1 := buildMap1()
 	type args struct {
@@ -232,3 +272,67 @@ func TestMap_ListCities(t *testing.T) {
 		})
 	}
 }
+
+func TestMap_Clone(t *testing.T) {
+	m := buildMap1()
+	m["C"].Aliens = []Alien{1}
+	clone := m.Clone()
+	if diff := m.Diff(clone); len(diff) != 0 {
+		t.Fatalf("Clone() diff = %v", diff)
+	}
+	for name, city := range clone {
+		if city == m[name] {
+			t.Fatalf("city %s is shared by the clone", name)
+		}
+		for d, next := range city.OutRoad {
+			if next != clone[next.Name] {
+				t.Errorf("road %s %v=%s of the clone leads to the original city", name, d, next)
+			}
+		}
+		for r := range city.inInroads {
+			if r.from != clone[r.from.Name] {
+				t.Errorf("in-road %s %v of the clone starts in the original city", r.from, r.direction)
+			}
+		}
+		if got, want := city.Directions(), m[name].Directions(); !reflect.DeepEqual(got, want) {
+			t.Errorf("city %s directions = %v, want %v", name, got, want)
+		}
+	}
+
+	clone.DestroyCity(clone["D"])
+	clone.DestroyRoad(clone["C"], West)
+	clone["C"].Aliens = append(clone["C"].Aliens, 2)
+	if diff := m.Diff(buildMap1()); len(diff) != 1 || diff[0] != "city C: aliens [1], got []" {
+		t.Errorf("original map is changed with the clone: %v", diff)
+	}
+}
+
+func TestMap_Diff(t *testing.T) {
+	changed := buildMap1()
+	changed.DestroyRoad(changed["C"], West)
+	changed.LinkCities("C", "T", West)
+	changed.LinkCities("E", "A", North)
+	changed.DestroyCity(changed["B"])
+	changed["J"].Aliens = []Alien{3}
+	want := []string{
+		"city B: missing",
+		"city C: missing road north to B",
+		"city C: road west to E, got T",
+		"city D: missing road north to B",
+		"city E: extra road north to A",
+		"city E: missing in-road B north",
+		"city E: missing in-road C west",
+		"city J: aliens [], got [3]",
+		"city T: missing road west to B",
+		"city T: missing in-road B east",
+		"city T: extra in-road C west",
+		"city A: extra",
+	}
+	m := buildMap1()
+	if got := m.Diff(changed); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %#v, want %#v", got, want)
+	}
+	if m.Equal(changed) || !m.Equal(buildMap1()) {
+		t.Errorf("Equal() mismatch")
+	}
+}
@@ -0,0 +1,61 @@
+package encoding
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"io"
+	"os"
+)
+
+// LoadDirections fetches built-in direction set by its name,
+// otherwise reads the user-supplied direction set from the file with the given path.
+func LoadDirections(name string) (*domain.DirectionSet, error) {
+	if set, ok := domain.DirectionSetByName(name); ok {
+		return set, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return UnmarshalDirections(f)
+}
+
+// UnmarshalDirections reads user-supplied direction set. Each line of the stream defines
+// a single direction followed by the name of its opposite direction. Comments and blank lines are ignored.
+//
+// For example, hex grid directions:
+//
+//	# hex grid
+//	northeast southwest
+//	east west
+//	southeast northwest
+//	southwest northeast
+//	west east
+//	northwest southeast
+func UnmarshalDirections(r io.Reader) (*domain.DirectionSet, error) {
+	var names, opposites []string
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		t := scanner.Text()
+		if isTrivia(t) {
+			continue
+		}
+		fields, err := splitFields(t)
+		if err == nil && (len(fields) != 2 || len(fields[0].parts) != 1 || len(fields[1].parts) != 1) {
+			err = fmt.Errorf("direction and its opposite are expected")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d \"%s\": %v", line, t, err)
+		}
+		names = append(names, fields[0].parts[0])
+		opposites = append(opposites, fields[1].parts[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return domain.NewDirectionSet(names, opposites)
+}
@@ -0,0 +1,79 @@
+package encoding
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalDirections(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name: "Hex grid",
+			src: `# hex grid
+northeast southwest
+east west
+
+southeast northwest
+southwest northeast
+west east
+northwest southeast
+`,
+			wantLen: 6,
+		},
+		{
+			name:    "Missing opposite",
+			src:     "in\nout in\n",
+			wantErr: true,
+		},
+		{
+			name:    "Empty set",
+			src:     "# nothing\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalDirections(strings.NewReader(tt.src))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalDirections() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Len() != tt.wantLen {
+				t.Errorf("UnmarshalDirections() Len() = %v, want %v", got.Len(), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestLoadDirections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hex.txt")
+	if err := os.WriteFile(path, []byte("east west\nwest east\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		wantLen int
+		wantErr bool
+	}{
+		{name: "layered", wantLen: 6},
+		{name: path, wantLen: 2},
+		{name: filepath.Join(t.TempDir(), "missing.txt"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.name), func(t *testing.T) {
+			got, err := LoadDirections(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadDirections() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Len() != tt.wantLen {
+				t.Errorf("LoadDirections() Len() = %v, want %v", got.Len(), tt.wantLen)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package encoding
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"io"
+	"strings"
+)
+
+// DOTOptions tunes Graphviz rendering of the Map
+type DOTOptions struct {
+	// Name of the graph. Default: "map"
+	Name string
+	// Destroyed lists names of the Cities which are not on the Map anymore.
+	// They are rendered as dashed red nodes without roads.
+	Destroyed []string
+	// Highlight fills Cities occupied by Aliens and labels them with the Aliens list.
+	Highlight bool
+	// Aliens names Aliens of the highlighted Cities. Aliens are labelled with their ids if it is nil.
+	Aliens *domain.AlienRegistry
+}
+
+// dotEscaper escapes characters which are not allowed inside the quoted DOT identifier
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// dotID quotes string as DOT identifier
+func dotID(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// MarshalDOT writes Map into io.Writer instance as a Graphviz directed graph.
+// Every road is an edge labelled with the road Direction.
+func MarshalDOT(w io.Writer, m domain.Map, opts DOTOptions) error {
+	name := opts.Name
+	if len(name) == 0 {
+		name = "map"
+	}
+	bw := bufio.NewWriter(w)
+	_, _ = fmt.Fprintf(bw, "digraph %s {\n", dotID(name))
+	for _, city := range m.ListCities() {
+		if opts.Highlight && len(city.Aliens) > 0 {
+			aliens := make([]string, 0, len(city.Aliens))
+			for _, alien := range city.Aliens {
+				aliens = append(aliens, opts.Aliens.Name(alien))
+			}
+			_, _ = fmt.Fprintf(bw, "\t%s [style=filled, fillcolor=gold, xlabel=%s];\n",
+				dotID(city.Name), dotID("aliens: "+strings.Join(aliens, ", ")))
+			continue
+		}
+		_, _ = fmt.Fprintf(bw, "\t%s;\n", dotID(city.Name))
+	}
+	for _, name := range opts.Destroyed {
+		_, _ = fmt.Fprintf(bw, "\t%s [style=dashed, color=red, fontcolor=red];\n", dotID(name))
+	}
+	for _, city := range m.ListCities() {
+		for _, dir := range city.Directions() {
+			_, _ = fmt.Fprintf(bw, "\t%s -> %s [label=%s];\n",
+				dotID(city.Name), dotID(city.OutRoad[dir].Name), dotID(dir.String()))
+		}
+	}
+	_, _ = fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
@@ -0,0 +1,89 @@
+package encoding
+
+import (
+	"bytes"
+	domain2 "github.com/zippunov/alien-invasion/internal/domain"
+	"testing"
+)
+
+func TestMarshalDOT(t *testing.T) {
+	m := domain2.Map{}
+	_ = m.LinkCities("aaa", "ddd", domain2.South)
+	_ = m.LinkCities("aaa", "e\"e", domain2.East)
+	m["ddd"].Aliens = append(m["ddd"].Aliens, 3, 5)
+	registry, _ := domain2.NewAlienRegistry([]string{"a0", "a1", "a2", "Zorg", "a4", "Big \"Bob\""})
+	type args struct {
+		m    domain2.Map
+		opts DOTOptions
+	}
+	tests := []struct {
+		name  string
+		args  args
+		wantW string
+	}{
+		{
+			name: "Empty map",
+			args: args{
+				m: domain2.Map{},
+			},
+			wantW: "digraph \"map\" {\n}\n",
+		},
+		{
+			name: "Plain map",
+			args: args{
+				m:    m,
+				opts: DOTOptions{Name: "before"},
+			},
+			wantW: `digraph "before" {
+	"aaa";
+	"ddd";
+	"e\"e";
+	"aaa" -> "ddd" [label="south"];
+	"aaa" -> "e\"e" [label="east"];
+}
+`,
+		},
+		{
+			name: "Highlighted map",
+			args: args{
+				m:    m,
+				opts: DOTOptions{Name: "after", Destroyed: []string{"bbb"}, Highlight: true},
+			},
+			wantW: `digraph "after" {
+	"aaa";
+	"ddd" [style=filled, fillcolor=gold, xlabel="aliens: 3, 5"];
+	"e\"e";
+	"bbb" [style=dashed, color=red, fontcolor=red];
+	"aaa" -> "ddd" [label="south"];
+	"aaa" -> "e\"e" [label="east"];
+}
+`,
+		},
+		{
+			name: "Named aliens",
+			args: args{
+				m:    m,
+				opts: DOTOptions{Highlight: true, Aliens: registry},
+			},
+			wantW: `digraph "map" {
+	"aaa";
+	"ddd" [style=filled, fillcolor=gold, xlabel="aliens: Zorg, Big \"Bob\""];
+	"e\"e";
+	"aaa" -> "ddd" [label="south"];
+	"aaa" -> "e\"e" [label="east"];
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			if err := MarshalDOT(w, tt.args.m, tt.args.opts); err != nil {
+				t.Fatalf("MarshalDOT() error = %v", err)
+			}
+			if gotW := w.String(); gotW != tt.wantW {
+				t.Errorf("MarshalDOT() gotW = %v, want %v", gotW, tt.wantW)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package encoding
+
+import (
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format is an integer value that identifies one of the supported Map formats.
+type Format uint
+
+// Enumeration of all supported formats
+const (
+	Txt Format = iota
+	JSON
+	DOT
+	// TxtCanonical is the Map text format written in the canonical form, it is read as Txt
+	TxtCanonical
+)
+
+// formatMap links string names to the Format enumeration
+var formatMap = map[string]Format{
+	"txt":           Txt,
+	"json":          JSON,
+	"dot":           DOT,
+	"txt-canonical": TxtCanonical,
+}
+
+// formatNames links Format enumeration to their names
+var formatNames = map[Format]string{
+	Txt:          "txt",
+	JSON:         "json",
+	DOT:          "dot",
+	TxtCanonical: "txt-canonical",
+}
+
+// FormatByName fetches Format by its name.
+// Returns (0, false) if given name not found
+func FormatByName(name string) (Format, bool) {
+	result, ok := formatMap[strings.ToLower(name)]
+	return result, ok
+}
+
+// FormatByPath detects Format by the file extension.
+// Returns (0, false) if the extension does not match any Format
+func FormatByPath(path string) (Format, bool) {
+	return FormatByName(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// String is a part of the Stringer interface implementation for the Format
+func (f Format) String() string {
+	return formatNames[f]
+}
+
+// Marshal writes Map into io.Writer instance according to the given Format.
+// Txt Map is written with the source Layout, nil Layout is empty. Other Formats ignore the Layout.
+func Marshal(w io.Writer, m domain.Map, f Format, layout *Layout) error {
+	switch f {
+	case Txt:
+		return MarshalTxtLayout(w, m, layout)
+	case TxtCanonical:
+		return MarshalTxtCanonical(w, m)
+	case JSON:
+		return MarshalJSON(w, m)
+	case DOT:
+		return MarshalDOT(w, m, DOTOptions{})
+	}
+	return fmt.Errorf("unsupported format %v", f)
+}
+
+// Unmarshal reads stream formatted according to the given Format
+// and fills Map with parsed Cities. DOT Format is write only.
+// Returns Layout of the Txt source, it is nil for other Formats.
+func Unmarshal(r io.Reader, m domain.Map, f Format) (*Layout, error) {
+	switch f {
+	case Txt, TxtCanonical:
+		return UnmarshalTxtLayout(r, m)
+	case JSON:
+		return nil, UnmarshalJSON(r, m)
+	}
+	return nil, fmt.Errorf("unsupported format %v", f)
+}
@@ -0,0 +1,48 @@
+package encoding
+
+import (
+	"bytes"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestFormatByPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   Format
+		wantOk bool
+	}{
+		{"map.txt", Txt, true},
+		{"dir/map.JSON", JSON, true},
+		{"map", Txt, false},
+		{"map.yaml", Txt, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := FormatByPath(tt.path)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("FormatByPath() = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestMarshal_canonical(t *testing.T) {
+	f, ok := FormatByName("txt-canonical")
+	if !ok || f != TxtCanonical {
+		t.Fatalf("FormatByName() = (%v, %v), want (%v, true)", f, ok, TxtCanonical)
+	}
+	m := domain.Map{}
+	layout, err := Unmarshal(strings.NewReader("B west=A north=C\nA east=B\n"), m, f)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	w := &bytes.Buffer{}
+	if err := Marshal(w, m, f, layout); err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := "A east=B\nB north=C west=A\n"; w.String() != want {
+		t.Errorf("Marshal() gotW = %q, want %q", w.String(), want)
+	}
+}
@@ -0,0 +1,96 @@
+package encoding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"io"
+	"sort"
+)
+
+// jsonMap is the root object of the Map JSON Format
+type jsonMap struct {
+	Cities []jsonCity `json:"cities"`
+}
+
+// jsonCity is the City object of the Map JSON Format
+type jsonCity struct {
+	Name   string            `json:"name"`
+	Roads  map[string]string `json:"roads"`
+	Aliens []domain.Alien    `json:"aliens,omitempty"`
+}
+
+// MarshalJSON writes Map into io.Writer instance according to Map JSON format
+func MarshalJSON(w io.Writer, m domain.Map) error {
+	doc := jsonMap{Cities: make([]jsonCity, 0, len(m))}
+	for _, city := range m.ListCities() {
+		jc := jsonCity{
+			Name:  city.Name,
+			Roads: make(map[string]string, len(city.OutRoad)),
+		}
+		for dir, neighbor := range city.OutRoad {
+			jc.Roads[dir.String()] = neighbor.Name
+		}
+		if len(city.Aliens) > 0 {
+			jc.Aliens = city.Aliens
+		}
+		doc.Cities = append(doc.Cities, jc)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// UnmarshalJSON reads stream formatted according to Map JSON Format
+// and fills Map with parsed Cities
+func UnmarshalJSON(r io.Reader, m domain.Map) error {
+	var doc jsonMap
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("invalid JSON map: %v", err)
+	}
+	defined := make(map[string]struct{}, len(doc.Cities))
+	for i, jc := range doc.Cities {
+		if err := parseJSONCity(jc, defined, m); err != nil {
+			return fmt.Errorf("invalid city #%d \"%s\": %v", i+1, jc.Name, err)
+		}
+	}
+	return nil
+}
+
+// parseJSONCity validates single City object and adds calls Map instance to create and link Map Cities
+func parseJSONCity(jc jsonCity, defined map[string]struct{}, m domain.Map) error {
+	if len(jc.Name) == 0 {
+		return errors.New("missing city name")
+	}
+	if _, ok := defined[jc.Name]; ok {
+		return errors.New("duplicate city definition")
+	}
+	defined[jc.Name] = struct{}{}
+	city := m.InitCity(jc.Name)
+	names := make([]string, 0, len(jc.Roads))
+	for name := range jc.Roads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		destination := jc.Roads[name]
+		direction, ok := domain.DirectionByName(name)
+		if !ok {
+			return fmt.Errorf("invalid direction name %s", name)
+		}
+		if len(destination) == 0 {
+			return errors.New("invalid neighbor encoding")
+		}
+		if err := m.LinkCities(jc.Name, destination, direction); err != nil {
+			return err
+		}
+	}
+	city.Aliens = append(city.Aliens, jc.Aliens...)
+	return nil
+}
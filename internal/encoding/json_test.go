@@ -0,0 +1,155 @@
+package encoding
+
+import (
+	"bytes"
+	domain2 "github.com/zippunov/alien-invasion/internal/domain"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	m := domain2.Map{}
+	_ = m.LinkCities("aaa", "ddd", domain2.South)
+	_ = m.LinkCities("aaa", "eee", domain2.East)
+	_ = m.LinkCities("eee", "aaa", domain2.West)
+	m["ddd"].Aliens = append(m["ddd"].Aliens, 3)
+	tests := []struct {
+		name  string
+		m     domain2.Map
+		wantW string
+	}{
+		{
+			name:  "Empty map",
+			m:     domain2.Map{},
+			wantW: `{"cities":[]}`,
+		},
+		{
+			name:  "Non empty map",
+			m:     m,
+			wantW: `{"cities":[{"name":"aaa","roads":{"east":"eee","south":"ddd"}},{"name":"ddd","roads":{},"aliens":[3]},{"name":"eee","roads":{"west":"aaa"}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			if err := MarshalJSON(w, tt.m); err != nil {
+				t.Fatalf("MarshalJSON() error = %v", err)
+			}
+			compact := strings.NewReplacer(" ", "", "\n", "").Replace(w.String())
+			if compact != tt.wantW {
+				t.Errorf("MarshalJSON() gotW = %v, want %v", compact, tt.wantW)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	type args struct {
+		r io.Reader
+		m domain2.Map
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		mapLen  int
+	}{
+		{
+			name: "Empty file",
+			args: args{
+				r: strings.NewReader(""),
+				m: domain2.Map{},
+			},
+			mapLen: 0,
+		},
+		{
+			name: "Malformed JSON",
+			args: args{
+				r: strings.NewReader(`{"cities": [`),
+				m: domain2.Map{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown field",
+			args: args{
+				r: strings.NewReader(`{"cities": [{"name": "aaa", "population": 3}]}`),
+				m: domain2.Map{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid direction",
+			args: args{
+				r: strings.NewReader(`{"cities": [{"name": "aaa", "roads": {"top": "bbb"}}]}`),
+				m: domain2.Map{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "City link to itself",
+			args: args{
+				r: strings.NewReader(`{"cities": [{"name": "aaa", "roads": {"west": "aaa"}}]}`),
+				m: domain2.Map{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Duplicate city",
+			args: args{
+				r: strings.NewReader(`{"cities": [{"name": "aaa", "roads": {"west": "bbb"}}, {"name": "aaa"}]}`),
+				m: domain2.Map{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing city name",
+			args: args{
+				r: strings.NewReader(`{"cities": [{"roads": {"west": "bbb"}}]}`),
+				m: domain2.Map{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "File with 4 cities",
+			args: args{
+				r: strings.NewReader(`{"cities": [
+					{"name": "aaa", "roads": {"west": "bbb", "north": "ddd"}},
+					{"name": "bbb", "roads": {"south": "ccc", "west": "aaa"}, "aliens": [1]}
+				]}`),
+				m: domain2.Map{},
+			},
+			mapLen: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := UnmarshalJSON(tt.args.r, tt.args.m); (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			} else if err == nil && tt.mapLen != len(tt.args.m) {
+				t.Errorf("UnmarshalJSON() invalid map length want %d, actual %d", tt.mapLen, len(tt.args.m))
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	src := `{"cities": [
+		{"name": "aaa", "roads": {"west": "bbb", "north": "ddd"}},
+		{"name": "bbb", "roads": {"south": "ccc", "west": "aaa"}, "aliens": [1]}
+	]}`
+	m1, m2 := domain2.Map{}, domain2.Map{}
+	if err := UnmarshalJSON(strings.NewReader(src), m1); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	w1, w2 := &bytes.Buffer{}, &bytes.Buffer{}
+	_ = MarshalJSON(w1, m1)
+	if err := UnmarshalJSON(bytes.NewReader(w1.Bytes()), m2); err != nil {
+		t.Fatalf("UnmarshalJSON() error = %v", err)
+	}
+	_ = MarshalJSON(w2, m2)
+	if w1.String() != w2.String() {
+		t.Errorf("round trip mismatch:\n%v\n%v", w1, w2)
+	}
+}
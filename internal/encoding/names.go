@@ -0,0 +1,25 @@
+package encoding
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// UnmarshalNames reads list of names, one name per line. Leading and trailing spaces are trimmed,
+// comments and blank lines are ignored.
+func UnmarshalNames(r io.Reader) ([]string, error) {
+	var names []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		t := scanner.Text()
+		if isTrivia(t) {
+			continue
+		}
+		names = append(names, strings.TrimSpace(t))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
@@ -0,0 +1,17 @@
+package encoding
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalNames(t *testing.T) {
+	got, err := UnmarshalNames(strings.NewReader("# crew\nZorg\n\n  Big Bob  \n# captain\nKang\n"))
+	if err != nil {
+		t.Fatalf("UnmarshalNames() error = %v", err)
+	}
+	if want := []string{"Zorg", "Big Bob", "Kang"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalNames() = %v, want %v", got, want)
+	}
+}
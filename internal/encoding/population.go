@@ -0,0 +1,54 @@
+package encoding
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// UnmarshalPopulation reads population of the Cities. Each line of the stream holds City name followed by
+// its population. Names follow the Map Text Format quoting rules, comments and blank lines are ignored.
+//
+//	# census
+//	Foo 1200
+//	"New York" 8000
+func UnmarshalPopulation(r io.Reader) (map[string]int, error) {
+	population := map[string]int{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		t := scanner.Text()
+		if isTrivia(t) {
+			continue
+		}
+		fields, err := splitFields(t)
+		if err == nil && (len(fields) != 2 || len(fields[0].parts) != 1 || len(fields[1].parts) != 1) {
+			err = fmt.Errorf("city and its population are expected")
+		}
+		if err == nil {
+			err = addPopulation(population, fields[0].parts[0], fields[1].parts[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d \"%s\": %v", line, t, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return population, nil
+}
+
+// addPopulation validates single population record and adds it to the population
+func addPopulation(population map[string]int, name, value string) error {
+	if _, ok := population[name]; ok {
+		return fmt.Errorf("duplicate population of city %s", name)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid population %s", value)
+	}
+	population[name] = n
+	return nil
+}
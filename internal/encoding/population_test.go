@@ -0,0 +1,48 @@
+package encoding
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalPopulation(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    map[string]int
+		wantErr bool
+	}{
+		{
+			name: "Census",
+			src:  "# census\nFoo 1200\n\n\"New York\" 8000 # big one\nBar 0\n",
+			want: map[string]int{"Foo": 1200, "New York": 8000, "Bar": 0},
+		},
+		{
+			name:    "Missing population",
+			src:     "Foo\n",
+			wantErr: true,
+		},
+		{
+			name:    "Negative population",
+			src:     "Foo -1\n",
+			wantErr: true,
+		},
+		{
+			name:    "Duplicate city",
+			src:     "Foo 1\nFoo 2\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalPopulation(strings.NewReader(tt.src))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalPopulation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalPopulation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package encoding
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ScenarioDefinition describes hand-built invasion: the map, parameters of the run and every Alien.
+// Zero values mean that the setting is not given and the application default is used.
+type ScenarioDefinition struct {
+	Map      string            `json:"map,omitempty"`      // path of the map file, relative to the scenario file
+	Seed     *int64            `json:"seed,omitempty"`     // seed of the random source
+	Moves    *int              `json:"moves,omitempty"`    // move budget of every Alien
+	Strategy string            `json:"strategy,omitempty"` // movement strategy spec of every Alien
+	Aliens   []AlienDefinition `json:"aliens,omitempty"`
+}
+
+// AlienDefinition describes single Alien of the scenario. Aliens get their integer ids in order of definition.
+type AlienDefinition struct {
+	Name     string `json:"name,omitempty"`
+	City     string `json:"city,omitempty"`     // starting City, random City if empty
+	Strategy string `json:"strategy,omitempty"` // overrides scenario movement strategy
+	Moves    *int   `json:"moves,omitempty"`    // overrides scenario move budget
+}
+
+// UnmarshalScenario reads scenario definition formatted according to the given Format.
+// Scenario can be defined with Txt or JSON Format.
+func UnmarshalScenario(r io.Reader, f Format) (ScenarioDefinition, error) {
+	switch f {
+	case Txt:
+		return UnmarshalScenarioTxt(r)
+	case JSON:
+		return UnmarshalScenarioJSON(r)
+	}
+	return ScenarioDefinition{}, fmt.Errorf("unsupported scenario format %v", f)
+}
+
+// UnmarshalScenarioTxt reads scenario definition in the text format. Each line holds a single setting
+// or a single Alien. Names follow the Map Text Format quoting rules, comments and blank lines are ignored.
+//
+//	# two aliens meet in the middle
+//	map small.txt
+//	seed 42
+//	moves 100
+//	strategy uniform
+//	alien Zorg city=Foo strategy=seek-nearest moves=10
+//	alien "Big Bob" city="New York"
+//	alien Kang
+func UnmarshalScenarioTxt(r io.Reader) (ScenarioDefinition, error) {
+	var def ScenarioDefinition
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		t := scanner.Text()
+		if isTrivia(t) {
+			continue
+		}
+		if err := def.parseLine(t); err != nil {
+			return ScenarioDefinition{}, fmt.Errorf("invalid line %d \"%s\": %v", line, t, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ScenarioDefinition{}, err
+	}
+	return def, nil
+}
+
+// parseLine applies single line of the scenario text format
+func (def *ScenarioDefinition) parseLine(s string) error {
+	fields, err := splitFields(s)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if len(f.parts[0]) == 0 {
+			return errors.New("missing setting name")
+		}
+	}
+	key := strings.ToLower(fields[0].parts[0])
+	if key == "alien" {
+		alien, err := parseAlienFields(fields[1:])
+		if err != nil {
+			return err
+		}
+		def.Aliens = append(def.Aliens, alien)
+		return nil
+	}
+	if len(fields) != 2 || len(fields[0].parts) != 1 || len(fields[1].parts) != 1 {
+		return fmt.Errorf("single value of the %s setting is expected", key)
+	}
+	value := fields[1].parts[0]
+	switch key {
+	case "map":
+		def.Map = value
+	case "strategy":
+		def.Strategy = value
+	case "seed":
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid seed %s", value)
+		}
+		def.Seed = &seed
+	case "moves":
+		moves, err := parseMoves(value)
+		if err != nil {
+			return err
+		}
+		def.Moves = &moves
+	default:
+		return fmt.Errorf("unknown setting %s", key)
+	}
+	return nil
+}
+
+// parseAlienFields parses optional Alien name followed by the key=value attributes
+func parseAlienFields(fields []field) (AlienDefinition, error) {
+	var alien AlienDefinition
+	if len(fields) > 0 && len(fields[0].parts) == 1 {
+		alien.Name = fields[0].parts[0]
+		fields = fields[1:]
+	}
+	for _, f := range fields {
+		if len(f.parts) < 2 {
+			return AlienDefinition{}, fmt.Errorf("attribute=value is expected, got %s", f.parts[0])
+		}
+		value := strings.Join(f.parts[1:], "=")
+		switch strings.ToLower(f.parts[0]) {
+		case "city":
+			alien.City = value
+		case "strategy":
+			alien.Strategy = value
+		case "moves":
+			moves, err := parseMoves(value)
+			if err != nil {
+				return AlienDefinition{}, err
+			}
+			alien.Moves = &moves
+		default:
+			return AlienDefinition{}, fmt.Errorf("unknown alien attribute %s", f.parts[0])
+		}
+	}
+	return alien, nil
+}
+
+// parseMoves parses non-negative move budget
+func parseMoves(value string) (int, error) {
+	moves, err := strconv.Atoi(value)
+	if err != nil || moves < 0 {
+		return 0, fmt.Errorf("invalid moves %s", value)
+	}
+	return moves, nil
+}
+
+// UnmarshalScenarioJSON reads scenario definition in JSON format. Attributes are named after
+// the text format settings, for example:
+//
+//	{
+//	  "map": "small.txt",
+//	  "seed": 42,
+//	  "aliens": [
+//	    {"name": "Zorg", "city": "Foo", "strategy": "seek-nearest", "moves": 10},
+//	    {"name": "Kang"}
+//	  ]
+//	}
+func UnmarshalScenarioJSON(r io.Reader) (ScenarioDefinition, error) {
+	var def ScenarioDefinition
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&def); err != nil {
+		return ScenarioDefinition{}, fmt.Errorf("invalid scenario: %v", err)
+	}
+	if def.Moves != nil && *def.Moves < 0 {
+		return ScenarioDefinition{}, fmt.Errorf("invalid moves %d", *def.Moves)
+	}
+	for i, alien := range def.Aliens {
+		if alien.Moves != nil && *alien.Moves < 0 {
+			return ScenarioDefinition{}, fmt.Errorf("invalid moves %d of alien %d", *alien.Moves, i)
+		}
+	}
+	return def, nil
+}
@@ -0,0 +1,124 @@
+package encoding
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestUnmarshalScenario(t *testing.T) {
+	want := ScenarioDefinition{
+		Map:      "maps/small.txt",
+		Seed:     int64Ptr(42),
+		Moves:    intPtr(100),
+		Strategy: "uniform",
+		Aliens: []AlienDefinition{
+			{Name: "Zorg", City: "Foo", Strategy: "weighted:north=2", Moves: intPtr(10)},
+			{Name: "Big Bob", City: "New York"},
+			{City: "Bar"},
+			{},
+		},
+	}
+	tests := []struct {
+		name    string
+		src     string
+		format  Format
+		want    ScenarioDefinition
+		wantErr bool
+	}{
+		{
+			name:   "Text",
+			format: Txt,
+			src: `# two aliens meet in the middle
+map maps/small.txt
+seed 42
+moves 100
+strategy uniform
+
+alien Zorg city=Foo strategy=weighted:north=2 moves=10 # the leader
+alien "Big Bob" city="New York"
+alien city=Bar
+alien
+`,
+			want: want,
+		},
+		{
+			name:   "JSON",
+			format: JSON,
+			src: `{
+  "map": "maps/small.txt",
+  "seed": 42,
+  "moves": 100,
+  "strategy": "uniform",
+  "aliens": [
+    {"name": "Zorg", "city": "Foo", "strategy": "weighted:north=2", "moves": 10},
+    {"name": "Big Bob", "city": "New York"},
+    {"city": "Bar"},
+    {}
+  ]
+}`,
+			want: want,
+		},
+		{
+			name:    "Unknown setting",
+			format:  Txt,
+			src:     "aliens 10\n",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown alien attribute",
+			format:  Txt,
+			src:     "alien Zorg color=green\n",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid seed",
+			format:  Txt,
+			src:     "seed forty-two\n",
+			wantErr: true,
+		},
+		{
+			name:    "Negative moves",
+			format:  Txt,
+			src:     "alien Zorg moves=-1\n",
+			wantErr: true,
+		},
+		{
+			name:    "Negative moves JSON",
+			format:  JSON,
+			src:     `{"aliens": [{"moves": -1}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "Unknown JSON attribute",
+			format:  JSON,
+			src:     `{"aliens": [{"color": "green"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "DOT format",
+			format:  DOT,
+			src:     "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalScenario(strings.NewReader(tt.src), tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalScenario() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalScenario() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ Package encoding is responsible for marshalling and unmarshalling domain.Map ins
 
 Map format is defined with one city per line. The city name is first, followed by 1-4 directions
 (north, south, east, or west). Each one represents a road to another city that lies in that direction.
+Other sets of directions can be activated with domain.UseDirections, the number of roads per city is limited
+by the size of the active set.
 
 For example:
 
@@ -13,96 +15,496 @@ For example:
 
 The city and each of the pairs are separated by a single space, and the directions are separated from their
 respective cities with an equals (=) sign.
+
+City names containing whitespaces, equals (=) sign or starting with the hash (#) sign are written in double quotes. Inside quotes,
+the backslash escapes double quote and backslash characters. Outside quotes, the backslash escapes any next character.
+
+	"New York" north="San Francisco" south=Salt\ Lake\ City
+	"Quote \" and backslash \\" east="New York"
+
+The hash (#) sign at the start of the line or the field begins a comment till the end of the line.
+Comments and blank lines are ignored by the decoder, but UnmarshalTxtLayout records them in the Layout
+together with the order of cities, line terminators (LF or CRLF) and the missing final line terminator.
+MarshalTxtLayout writes an untouched map back byte-for-byte.
+
+	# The World X
+	Foo north=Bar # the capital
+
+# Map JSON Format
+
+Map is a single JSON object with the list of cities. Each city has a unique name, an object of out-roads
+keyed by direction name (north, south, east, or west) with the destination city name as a value, and an
+optional list of alien IDs occupying the city. The occupancy is informational: the invasion run clears it
+and places its own aliens.
+
+For example:
+
+	{
+	  "cities": [
+	    {"name": "Foo", "roads": {"north": "Bar", "west": "Baz", "south": "Qu-ux"}},
+	    {"name": "Bar", "roads": {"south": "Foo", "west": "Bee"}, "aliens": [3]}
+	  ]
+	}
+
+Cities referenced by roads only are created as well. Unknown fields are rejected.
 */
 package encoding
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/zippunov/alien-invasion/internal/domain"
 	"io"
+	"sort"
 	"strings"
+	"unicode"
 )
 
-var newLine = []byte("\n")
+// commentPrefix starts the comment line of the Map text format
+const commentPrefix = "#"
 
-// MarshalTxt writes Map into io.Writer instance according to Map text format
+// roadRecord is a single parsed road of the City definition
+type roadRecord struct {
+	direction   domain.Direction
+	destination string
+}
+
+// cityRecord is a single parsed City definition line
+type cityRecord struct {
+	name  string
+	roads []roadRecord
+}
+
+// Layout records how the Map text source was written: order of the City definitions, definition lines
+// as they were read, comments, blank lines and line terminators. Layout is kept aside the Map and keyed
+// by City name, so the decoded Map carries no text format details.
+type Layout struct {
+	cities         map[string][]*cityLayout // definitions of every City, repeated definitions merge roads
+	order          []*cityLayout            // City definitions in order of the source
+	trailing       []string                 // lines without definitions (comments, blank lines) at the end of the source
+	noFinalNewline bool                     // the last line ends the source without the line terminator
+}
+
+// cityLayout is the source layout of the single City definition
+type cityLayout struct {
+	name    string   // name of the defined City
+	line    string   // definition line as it was written
+	leading []string // lines without definitions preceding the definition
+	newline string   // line terminator of the definition, "\n" or "\r\n"
+}
+
+// MarshalTxt writes Map into io.Writer instance according to Map text format. Cities are written sorted by name,
+// roads keep their order. Cities without out-roads which are known by in-roads only are omitted.
 func MarshalTxt(w io.Writer, m domain.Map) error {
+	return MarshalTxtLayout(w, m, nil)
+}
+
+// MarshalTxtLayout writes Map into io.Writer instance according to Map text format with the source Layout.
+//
+// Cities defined in the source keep their order, comments, blank lines and line terminators, the missing final
+// line terminator stays missing. Definitions of untouched Cities are written exactly as they were read, changed
+// ones keep the order of roads. The rest of the Cities follow sorted by name with line terminators of the first
+// City of the source. Cities without out-roads which are known by in-roads only are omitted. Nil Layout is empty.
+func MarshalTxtLayout(w io.Writer, m domain.Map, layout *Layout) error {
+	if layout == nil {
+		layout = &Layout{}
+	}
+	referenced := referencedCities(m)
+	var added []*domain.City
 	for _, city := range m.ListCities() {
-		if _, err := w.Write([]byte(city.Name)); err != nil {
-			return err
+		if _, ok := layout.cities[city.Name]; ok {
+			continue
+		}
+		if _, ok := referenced[city.Name]; ok && len(city.OutRoad) == 0 {
+			continue
 		}
-		for dir, neighbor := range city.OutRoad {
-			s := fmt.Sprintf(" %v=%v", dir, neighbor)
-			if _, err := w.Write([]byte(s)); err != nil {
-				return err
+		added = append(added, city)
+	}
+	first := "\n"
+	if len(layout.order) > 0 {
+		first = layout.order[0].newline
+	}
+	var lines []textLine
+	newline := first
+	written := make(map[string]bool, len(layout.cities))
+	for _, cl := range layout.order {
+		city, ok := m[cl.name]
+		if !ok {
+			continue
+		}
+		newline = cl.newline
+		for _, t := range cl.leading {
+			lines = append(lines, textLine{t, newline})
+		}
+		switch {
+		case isUntouched(city, layout.cities[cl.name]):
+			lines = append(lines, textLine{cl.line, newline})
+		case !written[cl.name]:
+			// changed City defined by several lines is written once in place of its first definition
+			lines = append(lines, textLine{formatCityLine(city, city.Directions()), newline})
+		}
+		written[cl.name] = true
+	}
+	// trailing lines get the terminator of the last written City
+	for _, t := range layout.trailing {
+		lines = append(lines, textLine{t, newline})
+	}
+	if layout.noFinalNewline && len(added) == 0 && len(lines) > 0 {
+		lines[len(lines)-1].newline = ""
+	}
+	for _, city := range added {
+		lines = append(lines, textLine{formatCityLine(city, city.Directions()), first})
+	}
+	return writeLines(w, lines)
+}
+
+// MarshalTxtCanonical writes Map into io.Writer instance according to Map text format in the canonical form.
+// Every City is written sorted by name, roads are sorted by direction, source layout is ignored.
+// Like MarshalTxt, Cities without out-roads which are known by in-roads only are omitted.
+// Canonical form does not depend on the Map history, which makes it suitable for golden files.
+func MarshalTxtCanonical(w io.Writer, m domain.Map) error {
+	referenced := referencedCities(m)
+	var lines []textLine
+	for _, city := range m.ListCities() {
+		if _, ok := referenced[city.Name]; ok && len(city.OutRoad) == 0 {
+			continue
+		}
+		dirs := city.Directions()
+		sort.Slice(dirs, func(i, j int) bool {
+			return dirs[i] < dirs[j]
+		})
+		lines = append(lines, textLine{formatCityLine(city, dirs), "\n"})
+	}
+	return writeLines(w, lines)
+}
+
+// formatCityLine formats City definition with roads in the given directions order
+func formatCityLine(city *domain.City, dirs []domain.Direction) string {
+	var b strings.Builder
+	b.WriteString(quoteName(city.Name))
+	for _, dir := range dirs {
+		_, _ = fmt.Fprintf(&b, " %v=%v", dir, quoteName(city.OutRoad[dir].Name))
+	}
+	return b.String()
+}
+
+// textLine is the line of the Map text with its line terminator, empty for the last line of the source without it
+type textLine struct {
+	text    string
+	newline string
+}
+
+// writeLines writes every given line followed by its line terminator
+func writeLines(w io.Writer, lines []textLine) error {
+	bw := bufio.NewWriter(w)
+	for _, line := range lines {
+		_, _ = bw.WriteString(line.text)
+		_, _ = bw.WriteString(line.newline)
+	}
+	return bw.Flush()
+}
+
+// isUntouched reports whether City roads are exactly the same as in its source definitions
+func isUntouched(city *domain.City, definitions []*cityLayout) bool {
+	roads := 0
+	for _, cl := range definitions {
+		record, err := parseCityRecord(cl.line)
+		if err != nil || record.name != city.Name {
+			return false
+		}
+		for _, r := range record.roads {
+			neighbor, ok := city.OutRoad[r.direction]
+			if !ok || neighbor.Name != r.destination {
+				return false
 			}
 		}
-		if _, err := w.Write(newLine); err != nil {
-			return err
+		roads += len(record.roads)
+	}
+	return roads == len(city.OutRoad)
+}
+
+// referencedCities returns set of Cities names which are destinations of any road
+func referencedCities(m domain.Map) map[string]struct{} {
+	result := make(map[string]struct{}, len(m))
+	for _, city := range m {
+		for _, neighbor := range city.OutRoad {
+			result[neighbor.Name] = struct{}{}
 		}
 	}
-	return nil
+	return result
 }
 
 // UnmarshalTxt reads stream formatted according to Map Text Format
 // and fills Map with parsed Cities
 func UnmarshalTxt(r io.Reader, m domain.Map) error {
+	_, err := UnmarshalTxtLayout(r, m)
+	return err
+}
+
+// UnmarshalTxtLayout reads stream formatted according to Map Text Format, fills Map with parsed Cities
+// and returns the source Layout for MarshalTxtLayout
+func UnmarshalTxtLayout(r io.Reader, m domain.Map) (*Layout, error) {
 	scanner := bufio.NewScanner(r)
+	scanner.Split(scanLines)
+	layout := &Layout{cities: map[string][]*cityLayout{}}
 	line := 0
+	var (
+		trivia  []string
+		newline = "\n" // terminator of the latest terminated line
+		final   bool   // the latest line ends the source without the terminator
+	)
 	for scanner.Scan() {
 		line++
-		t := scanner.Text()
-		if err := parseCityLine(t, m); err != nil {
-			return fmt.Errorf("invalid line %d \"%s\": %v", line, t, err)
+		t, eol := trimNewline(scanner.Text())
+		if final = len(eol) == 0; !final {
+			newline = eol
+		}
+		if isTrivia(t) {
+			trivia = append(trivia, t)
+			continue
+		}
+		record, err := parseCityRecord(t)
+		if err == nil {
+			err = applyCityRecord(record, m)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d \"%s\": %v", line, t, err)
 		}
+		cl := &cityLayout{name: record.name, line: t, leading: trivia, newline: newline}
+		layout.cities[record.name] = append(layout.cities[record.name], cl)
+		layout.order = append(layout.order, cl)
+		trivia = nil
 	}
-	return nil
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	layout.trailing, layout.noFinalNewline = trivia, final
+	return layout, nil
+}
+
+// scanLines is the split function of the Scanner returning every line together with its terminator,
+// so the decoder records line terminators of the source
+func scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// trimNewline splits the line returned by scanLines into its content and the terminator,
+// the terminator is empty for the last line of the source without it
+func trimNewline(s string) (string, string) {
+	if strings.HasSuffix(s, "\r\n") {
+		return s[:len(s)-2], "\r\n"
+	}
+	if strings.HasSuffix(s, "\n") {
+		return s[:len(s)-1], "\n"
+	}
+	return s, ""
+}
+
+// isTrivia reports whether the line has no City definition: it is either blank or a comment
+func isTrivia(s string) bool {
+	t := strings.TrimSpace(s)
+	return len(t) == 0 || strings.HasPrefix(t, commentPrefix)
 }
 
 // parseCityLine validates single map file line, parses it
 // and adds calls Map instance to create and link Map Cities
 func parseCityLine(s string, m domain.Map) error {
-	tokens := trimAndFilter(strings.Split(s, " "))
-	if len(tokens) < 1 {
-		return errors.New("empty line")
+	record, err := parseCityRecord(s)
+	if err != nil {
+		return err
+	}
+	return applyCityRecord(record, m)
+}
+
+// parseCityRecord validates single map file line and parses it into the cityRecord.
+// The first found problem is returned as an error.
+func parseCityRecord(s string) (cityRecord, error) {
+	record, problems := scanCityRecord(s)
+	if len(problems) > 0 {
+		return cityRecord{}, problems[0]
+	}
+	return record, nil
+}
+
+// scanCityRecord parses single map file line into the cityRecord and collects every problem of the line.
+// Problems are sorted by column, Line of the problems is not set.
+func scanCityRecord(s string) (cityRecord, []Problem) {
+	fields, err := splitFields(s)
+	if err != nil {
+		var p Problem
+		errors.As(err, &p)
+		// City name is recovered from the line prefix preceding the syntax error
+		record := cityRecord{}
+		if prefix, err := splitFields(string([]rune(s)[:p.Column-1])); err == nil && len(prefix) > 1 {
+			record.name = prefix[0].parts[0]
+		}
+		return record, []Problem{p}
 	}
-	if len(tokens) < 2 {
-		return errors.New("city must have at least one outgoing road")
+	if len(fields) < 1 {
+		return cityRecord{}, []Problem{{Column: 1, Message: "empty line"}}
 	}
-	if len(tokens) > 5 {
-		return errors.New("city must have at most four outgoing road")
+	var problems []Problem
+	report := func(column int, format string, a ...any) {
+		problems = append(problems, Problem{Column: column, Message: fmt.Sprintf(format, a...)})
 	}
-	cityFrom := tokens[0]
-	m.InitCity(cityFrom)
-	for i := 1; i < len(tokens); i++ {
-		roadString := tokens[i]
-		roadTokens := trimAndFilter(strings.Split(roadString, "="))
-		if len(roadTokens) < 2 {
-			return errors.New("invalid neighbor encoding")
+	name := fields[0]
+	if len(name.parts) != 1 || len(name.parts[0]) == 0 {
+		report(name.columns[0], "invalid city name")
+	}
+	if len(fields) < 2 {
+		report(1, "city must have at least one outgoing road")
+	}
+	if limit := domain.ActiveDirections().Len(); len(fields) > limit+1 {
+		report(fields[limit+1].columns[0], "city must have at most %d outgoing roads", limit)
+	}
+	record := cityRecord{
+		name:  name.parts[0],
+		roads: make([]roadRecord, 0, len(fields)-1),
+	}
+	taken := make(map[domain.Direction]struct{}, len(fields)-1)
+	for _, f := range fields[1:] {
+		if len(f.parts) != 2 || len(f.parts[0]) == 0 || len(f.parts[1]) == 0 {
+			report(f.columns[0], "invalid neighbor encoding")
+			continue
 		}
-		direction, ok := domain.DirectionByName(roadTokens[0])
+		direction, ok := domain.DirectionByName(f.parts[0])
 		if !ok {
-			return errors.New("invalid direction name")
+			report(f.columns[0], "invalid direction name %s", f.parts[0])
+			continue
 		}
-		destination := roadTokens[1]
-		if err := m.LinkCities(cityFrom, destination, direction); err != nil {
-			return err
+		if _, ok := taken[direction]; ok {
+			report(f.columns[0], "direction %v is taken for city %v", direction, record.name)
+			continue
+		}
+		taken[direction] = struct{}{}
+		if f.parts[1] == record.name {
+			report(f.columns[1], "attempt to link city %v to itself", record.name)
+			continue
 		}
+		record.roads = append(record.roads, roadRecord{direction, f.parts[1]})
 	}
-	return nil
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Column < problems[j].Column
+	})
+	return record, problems
+}
+
+// field is a single whitespace separated token of the City definition line.
+// Field is split into parts by the equals (=) sign, columns holds 1-based position of each part.
+type field struct {
+	parts   []string
+	columns []int
+}
+
+// splitFields splits City definition line into whitespace separated fields,
+// every field is split into parts by the equals (=) sign.
+//
+// Quoted parts may contain whitespaces, equals and hash signs. Backslash escapes the next character
+// both inside and outside of quotes. Unquoted hash sign at the start of the field begins the comment.
+// Syntax error is reported as the Problem with the column.
+func splitFields(s string) ([]field, error) {
+	var (
+		fields  []field
+		current field
+		part    strings.Builder
+		inField bool
+	)
+	// startPart records column of the part if it is the first character of the part
+	startPart := func(i int) {
+		if len(current.columns) == len(current.parts) {
+			current.columns = append(current.columns, i+1)
+		}
+	}
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			if inField {
+				current.parts = append(current.parts, part.String())
+				fields = append(fields, current)
+				current, inField = field{}, false
+				part.Reset()
+			}
+		case r == '#' && !inField:
+			i = len(runes)
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, Problem{Column: i + 1, Message: "dangling escape character"}
+			}
+			startPart(i)
+			i++
+			part.WriteRune(runes[i])
+			inField = true
+		case r == '"':
+			startPart(i)
+			quote, closed := i, false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+					part.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					break
+				}
+				part.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, Problem{Column: quote + 1, Message: "unterminated quoted name"}
+			}
+			inField = true
+		case r == '=':
+			startPart(i)
+			current.parts = append(current.parts, part.String())
+			current.columns = append(current.columns, i+2)
+			part.Reset()
+			inField = true
+		default:
+			startPart(i)
+			part.WriteRune(r)
+			inField = true
+		}
+	}
+	if inField {
+		current.parts = append(current.parts, part.String())
+		fields = append(fields, current)
+	}
+	return fields, nil
 }
 
-// trimAndFilter takes slice of strings, trims each one and returns list of Non-empty string
-// the order of the outgoing strings is preserved in the result.
-func trimAndFilter(tokens []string) []string {
-	validTokens := make([]string, 0, len(tokens))
-	for i := 0; i < len(tokens); i++ {
-		t := strings.TrimSpace(tokens[i])
-		if len(t) > 0 {
-			validTokens = append(validTokens, t)
+// quoteName returns City name as it is written in the Map text format.
+// Names which can not be read back as is are quoted.
+func quoteName(name string) string {
+	if len(name) > 0 && !strings.HasPrefix(name, commentPrefix) &&
+		!strings.ContainsAny(name, `="\`) && strings.IndexFunc(name, unicode.IsSpace) < 0 {
+		return name
+	}
+	return `"` + nameEscaper.Replace(name) + `"`
+}
+
+// nameEscaper escapes characters which are not allowed inside the quoted name
+var nameEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// applyCityRecord calls Map instance to create and link Cities of the parsed definition
+func applyCityRecord(record cityRecord, m domain.Map) error {
+	m.InitCity(record.name)
+	for _, r := range record.roads {
+		if err := m.LinkCities(record.name, r.destination, r.direction); err != nil {
+			return err
 		}
 	}
-	return validTokens
+	return nil
 }
@@ -3,12 +3,17 @@ package encoding
 import (
 	"bytes"
 	domain2 "github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/domain/domaintest"
 	"io"
 	"reflect"
 	"strings"
 	"testing"
 )
 
+func TestMain(m *testing.M) {
+	domaintest.Main(m)
+}
+
 func TestMarshal(t *testing.T) {
 	type args struct {
 		m domain2.Map
@@ -91,6 +96,16 @@ bbb south=ccc west=aaa`),
 			},
 			mapLen: 4,
 		},
+		{
+			name: "File with repeated city",
+			args: args{
+				r: strings.NewReader(`aaa west=bbb
+bbb east=aaa
+aaa north=ccc`),
+				m: domain2.Map{},
+			},
+			mapLen: 3,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -195,6 +210,30 @@ func Test_parseCityLine(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Quoted names",
+			args: args{
+				s: `"New York" west="San Francisco" south=Salt\ Lake # comment`,
+				m: domain2.Map{},
+			},
+			mapLen: 3,
+		},
+		{
+			name: "Empty quoted name",
+			args: args{
+				s: `"" west=bbb`,
+				m: domain2.Map{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Road with two equals signs",
+			args: args{
+				s: "aaa west=bbb=ccc",
+				m: domain2.Map{},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -207,42 +246,311 @@ func Test_parseCityLine(t *testing.T) {
 	}
 }
 
-func Test_trimAndFilter(t *testing.T) {
-	type args struct {
-		tokens []string
+func Test_splitFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name: "Empty line",
+			s:    "",
+			want: nil,
+		},
+		{
+			name: "Plain fields",
+			s:    " aaa  west=bbb\tnorth=ccc ",
+			want: [][]string{{"aaa"}, {"west", "bbb"}, {"north", "ccc"}},
+		},
+		{
+			name: "Quoted names",
+			s:    `"New York" north="San = Francisco" west=""`,
+			want: [][]string{{"New York"}, {"north", "San = Francisco"}, {"west", ""}},
+		},
+		{
+			name: "Escaped names",
+			s:    `New\ York north="Say \"Hi\"" south=a\=b`,
+			want: [][]string{{"New York"}, {"north", `Say "Hi"`}, {"south", "a=b"}},
+		},
+		{
+			name: "Inline comment",
+			s:    `aaa west=C# # comment with "quote`,
+			want: [][]string{{"aaa"}, {"west", "C#"}},
+		},
+		{
+			name:    "Unterminated quote",
+			s:       `aaa west="bbb`,
+			wantErr: true,
+		},
+		{
+			name:    "Dangling escape",
+			s:       `aaa west=bbb\`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, err := splitFields(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got [][]string
+			for _, f := range fields {
+				got = append(got, f.parts)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitFields() = %q, want %q", got, tt.want)
+			}
+		})
 	}
+}
+
+func Test_quoteName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Foo", "Foo"},
+		{"Qu-ux", "Qu-ux"},
+		{"C#", "C#"},
+		{"New York", `"New York"`},
+		{"a=b", `"a=b"`},
+		{"#hash", `"#hash"`},
+		{`Say "Hi"\`, `"Say \"Hi\"\\"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quoteName(tt.name); got != tt.want {
+				t.Errorf("quoteName() = %v, want %v", got, tt.want)
+			}
+			fields, err := splitFields(quoteName(tt.name))
+			if err != nil || len(fields) != 1 || len(fields[0].parts) != 1 || fields[0].parts[0] != tt.name {
+				t.Errorf("splitFields(quoteName()) = %q, %v, want %q", fields, err, tt.name)
+			}
+		})
+	}
+}
+
+func TestMarshalTxtLayout_RoundTrip(t *testing.T) {
 	tests := []struct {
 		name string
-		args args
-		want []string
+		src  string
 	}{
-		// TODO: Add test cases.
 		{
-			name: "Empty slice",
-			args: args{
-				tokens: []string{},
-			},
-			want: []string{},
+			name: "Plain map",
+			src: `C west=E south=J east=D north=B
+J east=C south=D north=T
+B north=E east=T
+`,
 		},
 		{
-			name: "Slice with empty elements",
-			args: args{
-				tokens: []string{"", "", ""},
-			},
-			want: []string{},
+			name: "Comments, blank lines and extra spaces",
+			src: `# World X
+
+C west=E  south=J east=D north=B
+  # the middle
+J east=C south=D north=T
+
+B north=E east=T
+# end of the world
+`,
 		},
 		{
-			name: "Slice with non empty elements",
-			args: args{
-				tokens: []string{"1", "", "2"},
-			},
-			want: []string{"1", "2"},
+			name: "Quoted names and inline comments",
+			src: `"New York" west="San Francisco" # the big apple
+"San Francisco" east="New York"  north=Salt\ Lake
+`,
+		},
+		{
+			name: "City defined after being referenced",
+			src: `A north=Z
+Z south=A
+M east=A
+`,
+		},
+		{
+			name: "No final new line",
+			src:  "A north=B\nB south=A",
+		},
+		{
+			name: "City defined twice",
+			src:  "A north=B\nB south=A\n# more roads of A\nA east=C\n",
+		},
+		{
+			name: "No final new line after comment",
+			src:  "A north=B\nB south=A\n# end",
+		},
+		{
+			name: "CRLF line terminators",
+			src:  "# World X\r\nA north=B\r\n\r\nB south=A\r\n",
+		},
+		{
+			name: "CRLF without final new line",
+			src:  "A north=B\r\nB south=A",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := domain2.Map{}
+			layout, err := UnmarshalTxtLayout(strings.NewReader(tt.src), m)
+			if err != nil {
+				t.Fatalf("UnmarshalTxtLayout() error = %v", err)
+			}
+			w := &bytes.Buffer{}
+			if err := MarshalTxtLayout(w, m, layout); err != nil {
+				t.Fatalf("MarshalTxtLayout() error = %v", err)
+			}
+			if gotW := w.String(); gotW != tt.src {
+				t.Errorf("MarshalTxtLayout() gotW = %q, want %q", gotW, tt.src)
+			}
+		})
+	}
+}
+
+func TestMarshalTxtLayout_Changed(t *testing.T) {
+	src := `# World X
+C west=E south=J  east=D
+J east=C south=D
+D north=C
+`
+	m := domain2.Map{}
+	layout, err := UnmarshalTxtLayout(strings.NewReader(src), m)
+	if err != nil {
+		t.Fatalf("UnmarshalTxtLayout() error = %v", err)
+	}
+	m.DestroyCity(m["J"])
+	_ = m.LinkCities("C", "A", domain2.North)
+	_ = m.LinkCities("A", "D", domain2.West)
+	want := `# World X
+C west=E east=D north=A
+D north=C
+A west=D
+`
+	w := &bytes.Buffer{}
+	if err := MarshalTxtLayout(w, m, layout); err != nil {
+		t.Fatalf("MarshalTxtLayout() error = %v", err)
+	}
+	if gotW := w.String(); gotW != want {
+		t.Errorf("MarshalTxtLayout() gotW = %q, want %q", gotW, want)
+	}
+}
+
+func TestMarshalTxtLayout_ChangedNewlines(t *testing.T) {
+	m := domain2.Map{}
+	layout, err := UnmarshalTxtLayout(strings.NewReader("A north=B\r\nB south=A"), m)
+	if err != nil {
+		t.Fatalf("UnmarshalTxtLayout() error = %v", err)
+	}
+	_ = m.LinkCities("C", "A", domain2.West)
+	// the added City follows the last line, which gets the terminator of the source
+	want := "A north=B\r\nB south=A\r\nC west=A\r\n"
+	w := &bytes.Buffer{}
+	if err := MarshalTxtLayout(w, m, layout); err != nil {
+		t.Fatalf("MarshalTxtLayout() error = %v", err)
+	}
+	if gotW := w.String(); gotW != want {
+		t.Errorf("MarshalTxtLayout() gotW = %q, want %q", gotW, want)
+	}
+}
+
+func TestMarshalTxtLayout_ChangedTwiceDefined(t *testing.T) {
+	m := domain2.Map{}
+	layout, err := UnmarshalTxtLayout(strings.NewReader("A north=B\nB south=A\n# more roads of A\nA east=C\n"), m)
+	if err != nil {
+		t.Fatalf("UnmarshalTxtLayout() error = %v", err)
+	}
+	m.DestroyCity(m["C"])
+	// the changed City merges its definitions in place of the first one
+	want := "A north=B\nB south=A\n# more roads of A\n"
+	w := &bytes.Buffer{}
+	if err := MarshalTxtLayout(w, m, layout); err != nil {
+		t.Fatalf("MarshalTxtLayout() error = %v", err)
+	}
+	if gotW := w.String(); gotW != want {
+		t.Errorf("MarshalTxtLayout() gotW = %q, want %q", gotW, want)
+	}
+}
+
+func TestMarshalTxtLayout_DestroyedLast(t *testing.T) {
+	m := domain2.Map{}
+	layout, err := UnmarshalTxtLayout(strings.NewReader("A north=B\nB south=A\nC west=A\n# end"), m)
+	if err != nil {
+		t.Fatalf("UnmarshalTxtLayout() error = %v", err)
+	}
+	m.DestroyCity(m["C"])
+	// comments at the end of the source outlive the last City
+	want := "A north=B\nB south=A\n# end"
+	w := &bytes.Buffer{}
+	if err := MarshalTxtLayout(w, m, layout); err != nil {
+		t.Fatalf("MarshalTxtLayout() error = %v", err)
+	}
+	if gotW := w.String(); gotW != want {
+		t.Errorf("MarshalTxtLayout() gotW = %q, want %q", gotW, want)
+	}
+}
+
+func TestMarshalTxtCanonical(t *testing.T) {
+	src := `# World X
+C west=E south=J east=D
+J east=C  south=D
+
+D north=C
+`
+	m := domain2.Map{}
+	if err := UnmarshalTxt(strings.NewReader(src), m); err != nil {
+		t.Fatalf("UnmarshalTxt() error = %v", err)
+	}
+	want := `C east=D south=J west=E
+D north=C
+J east=C south=D
+`
+	w := &bytes.Buffer{}
+	if err := MarshalTxtCanonical(w, m); err != nil {
+		t.Fatalf("MarshalTxtCanonical() error = %v", err)
+	}
+	if gotW := w.String(); gotW != want {
+		t.Errorf("MarshalTxtCanonical() gotW = %q, want %q", gotW, want)
+	}
+}
+
+func Test_parseCityLineDirections(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     string
+		s       string
+		wantErr bool
+	}{
+		{
+			name:    "Five roads in compass4",
+			set:     "compass4",
+			s:       "aaa north=b east=c south=d west=e north=f",
+			wantErr: true,
+		},
+		{
+			name: "Eight roads in compass8",
+			set:  "compass8",
+			s:    "aaa north=b east=c south=d west=e northeast=f southeast=g southwest=h northwest=i",
+		},
+		{
+			name:    "Up in compass8",
+			set:     "compass8",
+			s:       "aaa up=b",
+			wantErr: true,
+		},
+		{
+			name: "Up in layered",
+			set:  "layered",
+			s:    "aaa up=b down=c",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := trimAndFilter(tt.args.tokens); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("trimAndFilter() = %v, want %v", got, tt.want)
+			if !domaintest.RunWithDirections(t, tt.set) {
+				return
+			}
+			if err := parseCityLine(tt.s, domain2.Map{}); (err != nil) != tt.wantErr {
+				t.Errorf("parseCityLine() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
@@ -0,0 +1,359 @@
+package encoding
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Problem is a single issue found in the Map source. Line and Column are 1-based,
+// zero value means that position is unknown.
+type Problem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+// Error is the error interface implementation. Returns Problem message only.
+func (p Problem) Error() string {
+	return p.Message
+}
+
+// String is a part of the Stringer interface implementation. Prints Problem with its position if it is known.
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Message)
+}
+
+// Problems is the list of every issue found in the Map source
+type Problems []Problem
+
+// Error is the error interface implementation. Prints every Problem on its own line.
+func (ps Problems) Error() string {
+	lines := make([]string, 0, len(ps))
+	for _, p := range ps {
+		lines = append(lines, p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// definition holds position of the City definition or the first reference to the City
+type definition struct {
+	line   int
+	column int
+}
+
+// Validate reads stream formatted according to the given Format and collects every problem of the Map source.
+// Returned error reports failure of the reading only.
+func Validate(r io.Reader, f Format) (Problems, error) {
+	switch f {
+	case Txt, TxtCanonical:
+		return ValidateTxt(r)
+	case JSON:
+		return ValidateJSON(r)
+	}
+	return nil, fmt.Errorf("unsupported format %v", f)
+}
+
+// ValidateTxt reads stream formatted according to Map Text Format and collects every problem of the Map source.
+// Unlike UnmarshalTxt it does not stop on the first problem and applies additional checks:
+// every City must be defined once on its own line. Problems are sorted by position.
+// Returned error reports failure of the reading only.
+func ValidateTxt(r io.Reader) (Problems, error) {
+	var problems Problems
+	defined := map[string]definition{}
+	referenced := map[string]definition{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		t := scanner.Text()
+		if isTrivia(t) {
+			continue
+		}
+		record, lineProblems := scanCityRecord(t)
+		for _, p := range lineProblems {
+			p.Line = line
+			problems = append(problems, p)
+		}
+		if len(record.name) == 0 {
+			continue
+		}
+		if first, ok := defined[record.name]; ok {
+			problems = append(problems, Problem{
+				Line:    line,
+				Column:  1,
+				Message: fmt.Sprintf("duplicate definition of city %v, first defined at line %d", record.name, first.line),
+			})
+		} else {
+			defined[record.name] = definition{line, 1}
+		}
+		fields, err := splitFields(t)
+		if err != nil {
+			continue
+		}
+		for _, f := range fields[1:] {
+			if len(f.parts) != 2 || len(f.parts[1]) == 0 {
+				continue
+			}
+			if _, ok := referenced[f.parts[1]]; !ok {
+				referenced[f.parts[1]] = definition{line, f.columns[1]}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for name, ref := range referenced {
+		if _, ok := defined[name]; !ok {
+			problems = append(problems, Problem{
+				Line:    ref.line,
+				Column:  ref.column,
+				Message: fmt.Sprintf("city %v is never defined on its own line", name),
+			})
+		}
+	}
+	sortProblems(problems)
+	return problems, nil
+}
+
+// ValidateJSON reads stream formatted according to Map JSON Format and collects every problem of the Map source.
+// Unlike UnmarshalJSON it does not stop on the first invalid City, every problem is reported at the position
+// of the City, road or field it is found in. Problems are sorted by position.
+// Returned error reports failure of the reading only.
+func ValidateJSON(r io.Reader) (Problems, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(src)) == 0 {
+		return nil, nil
+	}
+	var problems Problems
+	report := func(offset int64, format string, a ...any) {
+		line, column := sourcePosition(src, offset)
+		problems = append(problems, Problem{Line: line, Column: column, Message: fmt.Sprintf(format, a...)})
+	}
+	var v any
+	if err := json.Unmarshal(src, &v); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) && syntaxErr.Offset > 0 {
+			// the offset follows the invalid character
+			report(syntaxErr.Offset-1, "invalid JSON map: %v", err)
+		} else {
+			problems = append(problems, Problem{Message: fmt.Sprintf("invalid JSON map: %v", err)})
+		}
+		return problems, nil
+	}
+	start := nextToken(src, 0)
+	root := jsonValue{src: src, offset: start, raw: src[start:]}
+	if !root.isObject() {
+		report(root.offset, "map must be a JSON object")
+		return problems, nil
+	}
+	defined := map[string]int{}
+	for _, f := range root.fields() {
+		if f.name != "cities" {
+			report(f.offset, "unknown field %q", f.name)
+			continue
+		}
+		if f.value.isNull() {
+			continue
+		}
+		if !f.value.isArray() {
+			report(f.value.offset, "cities must be a JSON array")
+			continue
+		}
+		for _, city := range f.value.elements() {
+			validateJSONCity(city, defined, report)
+		}
+	}
+	if len(problems) == 0 {
+		// the decoder has the final word on the checks the walk above does not repeat
+		if err := UnmarshalJSON(bytes.NewReader(src), domain.Map{}); err != nil {
+			problems = append(problems, Problem{Message: err.Error()})
+		}
+	}
+	sortProblems(problems)
+	return problems, nil
+}
+
+// validateJSONCity reports every problem of the single City object. defined holds lines
+// of the City definitions found so far.
+func validateJSONCity(city jsonValue, defined map[string]int, report func(int64, string, ...any)) {
+	if !city.isObject() {
+		report(city.offset, "city must be a JSON object")
+		return
+	}
+	var (
+		name  string
+		roads jsonValue
+	)
+	for _, f := range city.fields() {
+		switch f.name {
+		case "name":
+			if err := json.Unmarshal(f.value.raw, &name); err != nil {
+				report(f.value.offset, "city name must be a string")
+			}
+		case "roads":
+			if roads = f.value; !roads.isObject() && !roads.isNull() {
+				report(f.value.offset, "roads must be a JSON object")
+			}
+		case "aliens":
+			var aliens []domain.Alien
+			if err := json.Unmarshal(f.value.raw, &aliens); err != nil {
+				report(f.value.offset, "aliens must be a list of alien IDs")
+			}
+		default:
+			report(f.offset, "unknown field %q", f.name)
+		}
+	}
+	if len(name) == 0 {
+		report(city.offset, "missing city name")
+	} else if first, ok := defined[name]; ok {
+		report(city.offset, "duplicate definition of city %v, first defined at line %d", name, first)
+	} else {
+		defined[name], _ = city.position()
+	}
+	if !roads.isObject() {
+		return
+	}
+	taken := map[domain.Direction]struct{}{}
+	for _, f := range roads.fields() {
+		direction, ok := domain.DirectionByName(f.name)
+		if !ok {
+			report(f.offset, "invalid direction name %s", f.name)
+			continue
+		}
+		if _, ok := taken[direction]; ok {
+			report(f.offset, "direction %v is taken for city %v", direction, name)
+			continue
+		}
+		taken[direction] = struct{}{}
+		var destination string
+		if err := json.Unmarshal(f.value.raw, &destination); err != nil || len(destination) == 0 {
+			report(f.value.offset, "invalid neighbor encoding")
+			continue
+		}
+		if destination == name {
+			report(f.value.offset, "attempt to link city %v to itself", name)
+		}
+	}
+}
+
+// jsonValue is the JSON value of the Map source with its offset. Offsets of the nested values
+// are offsets in the whole source as well.
+type jsonValue struct {
+	src    []byte
+	offset int64
+	raw    json.RawMessage
+}
+
+// jsonField is the member of the JSON object with the offset of its name
+type jsonField struct {
+	name   string
+	offset int64
+	value  jsonValue
+}
+
+// isObject reports whether the value is a JSON object
+func (v jsonValue) isObject() bool {
+	return len(v.raw) > 0 && v.raw[0] == '{'
+}
+
+// isArray reports whether the value is a JSON array
+func (v jsonValue) isArray() bool {
+	return len(v.raw) > 0 && v.raw[0] == '['
+}
+
+// isNull reports whether the value is the JSON null
+func (v jsonValue) isNull() bool {
+	return string(v.raw) == "null"
+}
+
+// position returns 1-based line and column of the value in the source
+func (v jsonValue) position() (int, int) {
+	return sourcePosition(v.src, v.offset)
+}
+
+// fields lists members of the JSON object in order of the source, repeated names included
+func (v jsonValue) fields() []jsonField {
+	dec := json.NewDecoder(bytes.NewReader(v.raw))
+	if _, err := dec.Token(); err != nil {
+		return nil
+	}
+	var fields []jsonField
+	for dec.More() {
+		offset := nextToken(v.raw, dec.InputOffset())
+		t, err := dec.Token()
+		if err != nil {
+			return fields
+		}
+		name, _ := t.(string)
+		f := jsonField{name: name, offset: v.offset + offset}
+		if f.value, err = v.decode(dec); err != nil {
+			return fields
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// elements lists elements of the JSON array in order of the source
+func (v jsonValue) elements() []jsonValue {
+	dec := json.NewDecoder(bytes.NewReader(v.raw))
+	if _, err := dec.Token(); err != nil {
+		return nil
+	}
+	var elements []jsonValue
+	for dec.More() {
+		e, err := v.decode(dec)
+		if err != nil {
+			return elements
+		}
+		elements = append(elements, e)
+	}
+	return elements
+}
+
+// decode reads the next value of the decoder reading v.raw
+func (v jsonValue) decode(dec *json.Decoder) (jsonValue, error) {
+	next := jsonValue{src: v.src, offset: v.offset + nextToken(v.raw, dec.InputOffset())}
+	err := dec.Decode(&next.raw)
+	return next, err
+}
+
+// nextToken skips whitespaces and separators of the JSON source starting from the given offset,
+// returns offset of the next token
+func nextToken(src []byte, offset int64) int64 {
+	for offset < int64(len(src)) && strings.IndexByte(" \t\r\n,:", src[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// sourcePosition converts byte offset of the source into 1-based line and column, columns count runes
+func sourcePosition(src []byte, offset int64) (int, int) {
+	before := src[:offset]
+	start := bytes.LastIndexByte(before, '\n') + 1
+	return bytes.Count(before, []byte("\n")) + 1, utf8.RuneCount(before[start:]) + 1
+}
+
+// sortProblems sorts problems by position, problems without position go first
+func sortProblems(problems Problems) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+}
@@ -0,0 +1,139 @@
+package encoding
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateTxt(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want Problems
+	}{
+		{
+			name: "Valid map",
+			src: `# World X
+aaa west=bbb north="c c"
+
+bbb east=aaa
+"c c" south=aaa
+`,
+			want: nil,
+		},
+		{
+			name: "Every problem reported",
+			src: `aaa top=bbb west=bbb west=ccc
+bbb east=bbb south="ddd
+aaa north=bbb
+ccc
+eee north=a south=b east=c west=d north=e
+fff west=
+`,
+			want: Problems{
+				{Line: 1, Column: 5, Message: "invalid direction name top"},
+				{Line: 1, Column: 22, Message: "direction west is taken for city aaa"},
+				{Line: 2, Column: 20, Message: "unterminated quoted name"},
+				{Line: 3, Column: 1, Message: "duplicate definition of city aaa, first defined at line 1"},
+				{Line: 4, Column: 1, Message: "city must have at least one outgoing road"},
+				{Line: 5, Column: 11, Message: "city a is never defined on its own line"},
+				{Line: 5, Column: 19, Message: "city b is never defined on its own line"},
+				{Line: 5, Column: 26, Message: "city c is never defined on its own line"},
+				{Line: 5, Column: 33, Message: "city d is never defined on its own line"},
+				{Line: 5, Column: 35, Message: "city must have at most 4 outgoing roads"},
+				{Line: 5, Column: 35, Message: "direction north is taken for city eee"},
+				{Line: 5, Column: 41, Message: "city e is never defined on its own line"},
+				{Line: 6, Column: 5, Message: "invalid neighbor encoding"},
+			},
+		},
+		{
+			name: "Self link",
+			src: `aaa west=aaa
+`,
+			want: Problems{
+				{Line: 1, Column: 10, Message: "attempt to link city aaa to itself"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateTxt(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatalf("ValidateTxt() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateTxt() got:\n%v\nwant:\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want Problems
+	}{
+		{
+			name: "Valid map",
+			src: `{
+  "cities": [
+    {"name": "aaa", "roads": {"west": "bbb"}, "aliens": [1]},
+    {"name": "bbb", "roads": {"east": "aaa"}}
+  ]
+}
+`,
+			want: nil,
+		},
+		{
+			name: "Empty source",
+			src:  "\n",
+			want: nil,
+		},
+		{
+			name: "Every problem reported",
+			src: `{
+  "cities": [
+    {"name": "aaa", "roads": {"north": "bbb", "top": "ccc", "North": "ddd"}},
+    {"name": "bbb", "roads": {"south": "bbb", "east": ""}, "size": 3},
+    {"name": "aaa", "roads": {"west": 5}},
+    {"roads": {}},
+    "ccc"
+  ],
+  "version": 1
+}
+`,
+			want: Problems{
+				{Line: 3, Column: 47, Message: "invalid direction name top"},
+				{Line: 3, Column: 61, Message: "direction north is taken for city aaa"},
+				{Line: 4, Column: 40, Message: "attempt to link city bbb to itself"},
+				{Line: 4, Column: 55, Message: "invalid neighbor encoding"},
+				{Line: 4, Column: 60, Message: "unknown field \"size\""},
+				{Line: 5, Column: 5, Message: "duplicate definition of city aaa, first defined at line 3"},
+				{Line: 5, Column: 39, Message: "invalid neighbor encoding"},
+				{Line: 6, Column: 5, Message: "missing city name"},
+				{Line: 7, Column: 5, Message: "city must be a JSON object"},
+				{Line: 9, Column: 3, Message: "unknown field \"version\""},
+			},
+		},
+		{
+			name: "Syntax error",
+			src:  "{\"cities\": [\n  {\"name\": \"aaa\",}\n]}\n",
+			want: Problems{
+				{Line: 2, Column: 18, Message: "invalid JSON map: invalid character '}' looking for beginning of object key string"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateJSON(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatalf("ValidateJSON() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateJSON() got:\n%v\nwant:\n%v", got, tt.want)
+			}
+		})
+	}
+}
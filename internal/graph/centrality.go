@@ -0,0 +1,69 @@
+package graph
+
+// Centrality describes importance of the City for the movement of Aliens
+type Centrality struct {
+	City         string
+	In           int     // number of in-roads
+	Out          int     // number of out-roads
+	Closeness    float64 // harmonic closeness: mean of 1/distance to every other City, 0 for unreachable Cities
+	Betweenness  float64 // share of the shortest paths between other Cities passing through the City
+	Eccentricity int     // distance to the most remote reachable City
+}
+
+// Centrality computes degree and centrality metrics of every City, sorted by City name.
+// Every City is visited with BFS from every other City, so the cost is number of Cities times number of roads.
+func (g *Graph) Centrality() []Centrality {
+	n := len(g.names)
+	result := make([]Centrality, n)
+	for v := range result {
+		result[v] = Centrality{City: g.names[v], In: len(g.in[v]), Out: len(g.out[v])}
+	}
+	betweenness := make([]float64, n)
+	dist := make([]int, n)
+	sigma := make([]float64, n) // number of the shortest paths from the source
+	delta := make([]float64, n) // dependency of the source on the City
+	order := make([]int, 0, n)  // Cities in order of BFS
+	for s := 0; s < n; s++ {
+		for v := range dist {
+			dist[v], sigma[v], delta[v] = -1, 0, 0
+		}
+		dist[s], sigma[s] = 0, 1
+		order = append(order[:0], s)
+		for i := 0; i < len(order); i++ {
+			v := order[i]
+			for _, w := range g.out[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					order = append(order, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+				}
+			}
+		}
+		closeness := 0.0
+		for _, v := range order[1:] {
+			closeness += 1 / float64(dist[v])
+		}
+		if n > 1 {
+			result[s].Closeness = closeness / float64(n-1)
+		}
+		result[s].Eccentricity = dist[order[len(order)-1]]
+		// Brandes accumulation of dependencies in order of decreasing distance
+		for i := len(order) - 1; i > 0; i-- {
+			w := order[i]
+			for _, v := range g.in[w] {
+				if dist[v] >= 0 && dist[v] == dist[w]-1 {
+					delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+				}
+			}
+			betweenness[w] += delta[w]
+		}
+	}
+	if n > 2 {
+		for v := range result {
+			result[v].Betweenness = betweenness[v] / float64((n-1)*(n-2))
+		}
+	}
+	return result
+}
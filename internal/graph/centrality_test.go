@@ -0,0 +1,55 @@
+package graph
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"math"
+	"testing"
+)
+
+func TestGraph_Centrality(t *testing.T) {
+	tests := []struct {
+		name string
+		g    *Graph
+		want []Centrality
+	}{
+		{
+			name: "Line",
+			g:    buildGraph(t, link{"A", "B", domain.North}, link{"B", "C", domain.North}),
+			want: []Centrality{
+				{City: "A", In: 0, Out: 1, Closeness: 0.75, Betweenness: 0, Eccentricity: 2},
+				{City: "B", In: 1, Out: 1, Closeness: 0.5, Betweenness: 0.5, Eccentricity: 1},
+				{City: "C", In: 1, Out: 0, Closeness: 0, Betweenness: 0, Eccentricity: 0},
+			},
+		},
+		{
+			name: "Diamond",
+			g: buildGraph(t,
+				link{"A", "B", domain.North},
+				link{"A", "C", domain.East},
+				link{"B", "D", domain.East},
+				link{"C", "D", domain.North},
+			),
+			want: []Centrality{
+				{City: "A", In: 0, Out: 2, Closeness: (1 + 1 + 0.5) / 3, Eccentricity: 2},
+				{City: "B", In: 1, Out: 1, Closeness: 1.0 / 3, Betweenness: 1.0 / 12, Eccentricity: 1},
+				{City: "C", In: 1, Out: 1, Closeness: 1.0 / 3, Betweenness: 1.0 / 12, Eccentricity: 1},
+				{City: "D", In: 2, Out: 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.g.Centrality()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Centrality() = %v, want %v", got, tt.want)
+			}
+			for i, c := range got {
+				w := tt.want[i]
+				if c.City != w.City || c.In != w.In || c.Out != w.Out || c.Eccentricity != w.Eccentricity ||
+					math.Abs(c.Closeness-w.Closeness) > 1e-9 || math.Abs(c.Betweenness-w.Betweenness) > 1e-9 {
+					t.Errorf("Centrality() = %+v, want %+v", c, w)
+				}
+			}
+		})
+	}
+}
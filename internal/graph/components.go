@@ -0,0 +1,157 @@
+package graph
+
+// StronglyConnected lists strongly connected components: groups of Cities where every City is reachable
+// from every other one. Components are sorted by size, the largest first, Cities of the component by name.
+func (g *Graph) StronglyConnected() [][]string {
+	component, count := g.scc()
+	return g.groups(component, count)
+}
+
+// WeaklyConnected lists groups of Cities connected by roads regardless of the road direction.
+// Components are sorted by size, the largest first, Cities of the component by name.
+func (g *Graph) WeaklyConnected() [][]string {
+	component := make([]int, len(g.names))
+	for i := range component {
+		component[i] = -1
+	}
+	count := 0
+	for s := range g.names {
+		if component[s] >= 0 {
+			continue
+		}
+		component[s] = count
+		queue := []int{s}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for _, next := range [][]int{g.out[v], g.in[v]} {
+				for _, w := range next {
+					if component[w] < 0 {
+						component[w] = count
+						queue = append(queue, w)
+					}
+				}
+			}
+		}
+		count++
+	}
+	return g.groups(component, count)
+}
+
+// Traps lists strongly connected components without roads leading out of them, which can be entered
+// from other Cities. Aliens entering such component never leave it. Sinks with in-roads are traps as well.
+func (g *Graph) Traps() [][]string {
+	component, count := g.scc()
+	closed := make([]bool, count)
+	entered := make([]bool, count)
+	for i := range closed {
+		closed[i] = true
+	}
+	for v, out := range g.out {
+		for _, w := range out {
+			if component[v] != component[w] {
+				closed[component[v]] = false
+				entered[component[w]] = true
+			}
+		}
+	}
+	var result [][]string
+	for _, group := range g.groups(component, count) {
+		c := component[g.index[group[0]]]
+		if closed[c] && entered[c] {
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
+// FindCycle returns the shortest cycle of roads through the first City of the smallest strongly connected
+// component with a cycle. The first City is repeated at the end. Returns nil if the Graph has no cycles.
+func (g *Graph) FindCycle() []string {
+	component, count := g.scc()
+	groups := g.groups(component, count)
+	for i := len(groups) - 1; i >= 0; i-- {
+		if len(groups[i]) < 2 {
+			continue
+		}
+		s := g.index[groups[i][0]]
+		inComponent := func(v int) bool {
+			return component[v] == component[s]
+		}
+		var best []int
+		for _, w := range g.out[s] {
+			if !inComponent(w) {
+				continue
+			}
+			if p := g.path(w, s, inComponent); p != nil && (best == nil || len(p) < len(best)) {
+				best = p
+			}
+		}
+		return g.namesOf(append([]int{s}, best...))
+	}
+	return nil
+}
+
+// scc finds strongly connected components with the iterative Tarjan algorithm.
+// Returns component id of every City and the number of components.
+func (g *Graph) scc() ([]int, int) {
+	n := len(g.names)
+	order := make([]int, n) // discovery order starting from 1, 0 means not visited
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	component := make([]int, n)
+	var stack []int
+	type frame struct {
+		v    int
+		next int // index of the next out-road to follow
+	}
+	visited, count := 0, 0
+	visit := func(v int) {
+		visited++
+		order[v], low[v] = visited, visited
+		stack = append(stack, v)
+		onStack[v] = true
+	}
+	for root := 0; root < n; root++ {
+		if order[root] != 0 {
+			continue
+		}
+		visit(root)
+		calls := []frame{{v: root}}
+		for len(calls) > 0 {
+			f := &calls[len(calls)-1]
+			v := f.v
+			if f.next < len(g.out[v]) {
+				w := g.out[v][f.next]
+				f.next++
+				if order[w] == 0 {
+					visit(w)
+					calls = append(calls, frame{v: w})
+				} else if onStack[w] && order[w] < low[v] {
+					low[v] = order[w]
+				}
+				continue
+			}
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				if u := calls[len(calls)-1].v; low[v] < low[u] {
+					low[u] = low[v]
+				}
+			}
+			if low[v] != order[v] {
+				continue
+			}
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component[w] = count
+				if w == v {
+					break
+				}
+			}
+			count++
+		}
+	}
+	return component, count
+}
@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestGraph_StronglyConnected(t *testing.T) {
+	want := [][]string{{"A", "B", "C"}, {"D", "E"}, {"F"}, {"G"}, {"H"}}
+	if got := buildGraph1(t).StronglyConnected(); !reflect.DeepEqual(got, want) {
+		t.Errorf("StronglyConnected() = %v, want %v", got, want)
+	}
+}
+
+func TestGraph_WeaklyConnected(t *testing.T) {
+	want := [][]string{{"A", "B", "C", "D", "E", "F"}, {"G", "H"}}
+	if got := buildGraph1(t).WeaklyConnected(); !reflect.DeepEqual(got, want) {
+		t.Errorf("WeaklyConnected() = %v, want %v", got, want)
+	}
+	if got := New(domain.Map{}).WeaklyConnected(); len(got) != 0 {
+		t.Errorf("WeaklyConnected() of the empty map = %v", got)
+	}
+}
+
+func TestGraph_Traps(t *testing.T) {
+	want := [][]string{{"D", "E"}, {"H"}}
+	if got := buildGraph1(t).Traps(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Traps() = %v, want %v", got, want)
+	}
+	ring := buildGraph(t, link{"A", "B", domain.North}, link{"B", "C", domain.North}, link{"C", "A", domain.North})
+	if got := ring.Traps(); len(got) != 0 {
+		t.Errorf("Traps() of the ring = %v", got)
+	}
+}
+
+func TestGraph_FindCycle(t *testing.T) {
+	tests := []struct {
+		name string
+		g    *Graph
+		want []string
+	}{
+		{name: "Smallest component", g: buildGraph1(t), want: []string{"D", "E", "D"}},
+		{
+			name: "Shortest cycle",
+			g: buildGraph(t,
+				link{"A", "B", domain.North},
+				link{"B", "C", domain.North},
+				link{"C", "D", domain.North},
+				link{"D", "A", domain.North},
+				link{"A", "C", domain.East},
+				link{"C", "A", domain.West},
+			),
+			want: []string{"A", "C", "A"},
+		},
+		{name: "Acyclic", g: buildGraph(t, link{"A", "B", domain.North}, link{"B", "C", domain.North})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.g.FindCycle(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindCycle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraph_scc_deep(t *testing.T) {
+	// long ring checks that the component search does not depend on the recursion depth
+	m := domain.Map{}
+	n := 100000
+	name := func(i int) string {
+		return string(rune('a'+i%26)) + string(rune('a'+i/26%26)) + string(rune('a'+i/676%26)) + string(rune('a'+i/17576%26))
+	}
+	for i := 0; i < n; i++ {
+		if err := m.LinkCities(name(i), name((i+1)%n), domain.North); err != nil {
+			t.Fatalf("LinkCities() error = %v", err)
+		}
+	}
+	if got := New(m).StronglyConnected(); len(got) != 1 || len(got[0]) != n {
+		t.Errorf("StronglyConnected() found %d components", len(got))
+	}
+}
@@ -0,0 +1,6 @@
+/*
+Package graph provides analysis of the Map roads graph: connected components, reachability, shortest paths,
+dead ends, cycles and centrality of the Cities. Roads are directed, so every analysis honours road directions
+unless stated otherwise.
+*/
+package graph
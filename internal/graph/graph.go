@@ -0,0 +1,170 @@
+package graph
+
+import (
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"sort"
+)
+
+// Graph is the snapshot of the Map roads prepared for the analysis. Cities are indexed in order of their names,
+// so every result is stable. Later changes of the Map are not reflected by the Graph.
+type Graph struct {
+	names []string
+	index map[string]int
+	out   [][]int // targets of the out-roads of every City in order of the road directions
+	in    [][]int // sources of the in-roads of every City
+}
+
+// New creates the Graph of the given Map
+func New(m domain.Map) *Graph {
+	cities := m.ListCities()
+	g := &Graph{
+		names: make([]string, len(cities)),
+		index: make(map[string]int, len(cities)),
+		out:   make([][]int, len(cities)),
+		in:    make([][]int, len(cities)),
+	}
+	for i, city := range cities {
+		g.names[i] = city.Name
+		g.index[city.Name] = i
+	}
+	for i, city := range cities {
+		for _, d := range city.Directions() {
+			j := g.index[city.OutRoad[d].Name]
+			g.out[i] = append(g.out[i], j)
+			g.in[j] = append(g.in[j], i)
+		}
+	}
+	return g
+}
+
+// Len returns number of Cities of the Graph
+func (g *Graph) Len() int {
+	return len(g.names)
+}
+
+// Roads returns number of roads of the Graph
+func (g *Graph) Roads() int {
+	n := 0
+	for _, out := range g.out {
+		n += len(out)
+	}
+	return n
+}
+
+// Sinks lists dead-end Cities without out-roads. Alien entering such City is trapped there.
+func (g *Graph) Sinks() []string {
+	var result []string
+	for v, out := range g.out {
+		if len(out) == 0 {
+			result = append(result, g.names[v])
+		}
+	}
+	return result
+}
+
+// Reachable lists Cities reachable from the given City by at least one road, sorted by name.
+// The City itself is listed only if it lies on a cycle.
+func (g *Graph) Reachable(from string) ([]string, error) {
+	s, ok := g.index[from]
+	if !ok {
+		return nil, fmt.Errorf("city %s is not found", from)
+	}
+	seen := make([]bool, len(g.names))
+	queue := append([]int(nil), g.out[s]...)
+	for _, v := range queue {
+		seen[v] = true
+	}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range g.out[v] {
+			if !seen[w] {
+				seen[w] = true
+				queue = append(queue, w)
+			}
+		}
+	}
+	var result []string
+	for v, ok := range seen {
+		if ok {
+			result = append(result, g.names[v])
+		}
+	}
+	return result, nil
+}
+
+// ShortestPath returns the shortest sequence of Cities leading from one City to another along the roads.
+// Both Cities are included. Returns nil if the destination is unreachable.
+func (g *Graph) ShortestPath(from, to string) ([]string, error) {
+	s, ok := g.index[from]
+	if !ok {
+		return nil, fmt.Errorf("city %s is not found", from)
+	}
+	t, ok := g.index[to]
+	if !ok {
+		return nil, fmt.Errorf("city %s is not found", to)
+	}
+	path := g.path(s, t, nil)
+	if path == nil {
+		return nil, nil
+	}
+	return g.namesOf(path), nil
+}
+
+// path finds the shortest path from s to t with BFS. Only Cities allowed by the filter are visited,
+// nil filter allows every City. Returns nil if t is unreachable.
+func (g *Graph) path(s, t int, allowed func(v int) bool) []int {
+	prev := make([]int, len(g.names))
+	for i := range prev {
+		prev[i] = -1
+	}
+	prev[s] = s
+	queue := []int{s}
+	for len(queue) > 0 && prev[t] < 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range g.out[v] {
+			if prev[w] < 0 && (allowed == nil || allowed(w)) {
+				prev[w] = v
+				queue = append(queue, w)
+			}
+		}
+	}
+	if prev[t] < 0 {
+		return nil
+	}
+	result := []int{t}
+	for v := t; v != s; v = prev[v] {
+		result = append(result, prev[v])
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// namesOf converts City indices into City names
+func (g *Graph) namesOf(ids []int) []string {
+	result := make([]string, len(ids))
+	for i, v := range ids {
+		result[i] = g.names[v]
+	}
+	return result
+}
+
+// groups converts the component id of every City into the lists of City names.
+// Components are sorted by size, the largest first, then by the first City name.
+func (g *Graph) groups(component []int, count int) [][]string {
+	result := make([][]string, count)
+	for v, c := range component {
+		result[c] = append(result[c], g.names[v])
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i]) != len(result[j]) {
+			return len(result[i]) > len(result[j])
+		}
+		return result[i][0] < result[j][0]
+	})
+	return result
+}
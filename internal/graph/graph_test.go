@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"reflect"
+	"testing"
+)
+
+type link struct {
+	from, to  string
+	direction domain.Direction
+}
+
+func buildGraph(t *testing.T, links ...link) *Graph {
+	t.Helper()
+	m := domain.Map{}
+	for _, l := range links {
+		if err := m.LinkCities(l.from, l.to, l.direction); err != nil {
+			t.Fatalf("LinkCities() error = %v", err)
+		}
+	}
+	return New(m)
+}
+
+// buildGraph1 builds the map with A, B and C linked both ways, leading to the trap of D and E.
+// F leads to A, G leads to the dead end H.
+func buildGraph1(t *testing.T) *Graph {
+	return buildGraph(t,
+		link{"A", "B", domain.North},
+		link{"B", "C", domain.North},
+		link{"C", "B", domain.South},
+		link{"B", "A", domain.South},
+		link{"C", "D", domain.East},
+		link{"D", "E", domain.East},
+		link{"E", "D", domain.West},
+		link{"F", "A", domain.East},
+		link{"G", "H", domain.North},
+	)
+}
+
+func TestGraph_Sinks(t *testing.T) {
+	g := buildGraph1(t)
+	if got := g.Sinks(); !reflect.DeepEqual(got, []string{"H"}) {
+		t.Errorf("Sinks() = %v, want [H]", got)
+	}
+	if g.Len() != 8 || g.Roads() != 9 {
+		t.Errorf("Len() = %d, Roads() = %d, want 8 and 9", g.Len(), g.Roads())
+	}
+}
+
+func TestGraph_Reachable(t *testing.T) {
+	g := buildGraph1(t)
+	tests := []struct {
+		from    string
+		want    []string
+		wantErr bool
+	}{
+		{from: "A", want: []string{"A", "B", "C", "D", "E"}},
+		{from: "F", want: []string{"A", "B", "C", "D", "E"}},
+		{from: "G", want: []string{"H"}},
+		{from: "H"},
+		{from: "X", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from, func(t *testing.T) {
+			got, err := g.Reachable(tt.from)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reachable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reachable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraph_ShortestPath(t *testing.T) {
+	g := buildGraph1(t)
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "Along directions", from: "F", to: "E", want: []string{"F", "A", "B", "C", "D", "E"}},
+		{name: "Against directions", from: "E", to: "A"},
+		{name: "Same city", from: "A", to: "A", want: []string{"A"}},
+		{name: "Unknown city", from: "A", to: "X", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.ShortestPath(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ShortestPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ShortestPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
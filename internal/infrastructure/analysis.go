@@ -0,0 +1,144 @@
+package infrastructure
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/usecases"
+	"io"
+	"strings"
+)
+
+// jsonCentrality is JSON representation of the graph.Centrality
+type jsonCentrality struct {
+	City         string  `json:"city"`
+	In           int     `json:"in"`
+	Out          int     `json:"out"`
+	Closeness    float64 `json:"closeness"`
+	Betweenness  float64 `json:"betweenness"`
+	Eccentricity int     `json:"eccentricity"`
+}
+
+// jsonAnalysis is JSON representation of the usecases.AnalysisReport
+type jsonAnalysis struct {
+	Cities            int              `json:"cities"`
+	Roads             int              `json:"roads"`
+	OneWayRoads       int              `json:"one_way_roads"`
+	ConflictingRoads  int              `json:"conflicting_roads"`
+	Inconsistencies   []string         `json:"inconsistencies"`
+	Diameter          int              `json:"diameter"`
+	StronglyConnected [][]string       `json:"strongly_connected"`
+	WeaklyConnected   [][]string       `json:"weakly_connected"`
+	Sinks             []string         `json:"sinks"`
+	Traps             [][]string       `json:"traps"`
+	Cycle             []string         `json:"cycle"`
+	Centrality        []jsonCentrality `json:"centrality"`
+	From              string           `json:"from,omitempty"`
+	Reachable         []string         `json:"reachable,omitempty"`
+	To                string           `json:"to,omitempty"`
+	Path              []string         `json:"path,omitempty"`
+}
+
+// WriteAnalysis writes the report of the Map graph analysis into the output, text or JSON
+func (i *Infra) WriteAnalysis(report usecases.AnalysisReport) error {
+	return writeAnalysis(i.writer, report, i.reportJSON)
+}
+
+// writeAnalysis writes the report of the Map graph analysis, text or JSON
+func writeAnalysis(w io.Writer, report usecases.AnalysisReport, asJSON bool) error {
+	if asJSON {
+		centrality := make([]jsonCentrality, len(report.Centrality))
+		for i, c := range report.Centrality {
+			centrality[i] = jsonCentrality(c)
+		}
+		inconsistencies := make([]string, len(report.Inconsistencies))
+		for i, inconsistency := range report.Inconsistencies {
+			inconsistencies[i] = inconsistency.String()
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(jsonAnalysis{
+			Cities:            report.Cities,
+			Roads:             report.Roads,
+			OneWayRoads:       report.OneWayRoads,
+			ConflictingRoads:  report.ConflictingRoads,
+			Inconsistencies:   inconsistencies,
+			Diameter:          report.Diameter,
+			StronglyConnected: nonNilGroups(report.StronglyConnected),
+			WeaklyConnected:   nonNilGroups(report.WeaklyConnected),
+			Sinks:             nonNil(report.Sinks),
+			Traps:             nonNilGroups(report.Traps),
+			Cycle:             nonNil(report.Cycle),
+			Centrality:        centrality,
+			From:              report.From,
+			Reachable:         report.Reachable,
+			To:                report.To,
+			Path:              report.Path,
+		})
+	}
+	bw := bufio.NewWriter(w)
+	_, _ = fmt.Fprintf(bw, "cities: %d\n", report.Cities)
+	_, _ = fmt.Fprintf(bw, "roads: %d\n", report.Roads)
+	_, _ = fmt.Fprintf(bw, "one-way roads: %d\n", report.OneWayRoads)
+	_, _ = fmt.Fprintf(bw, "conflicting roads: %d\n", report.ConflictingRoads)
+	for _, i := range report.Inconsistencies {
+		_, _ = fmt.Fprintf(bw, "\t%s\n", i)
+	}
+	_, _ = fmt.Fprintf(bw, "diameter: %d\n", report.Diameter)
+	writeGroups(bw, "strongly connected components", report.StronglyConnected)
+	writeGroups(bw, "weakly connected components", report.WeaklyConnected)
+	_, _ = fmt.Fprintf(bw, "sinks: %s\n", joinOrNone(report.Sinks))
+	writeGroups(bw, "traps", report.Traps)
+	if report.Cycle == nil {
+		_, _ = fmt.Fprintln(bw, "cycle: none")
+	} else {
+		_, _ = fmt.Fprintf(bw, "cycle: %s\n", strings.Join(report.Cycle, " -> "))
+	}
+	if len(report.From) != 0 {
+		_, _ = fmt.Fprintf(bw, "reachable from %s: %s\n", report.From, joinOrNone(report.Reachable))
+	}
+	if len(report.To) != 0 {
+		if report.Path == nil {
+			_, _ = fmt.Fprintf(bw, "path from %s to %s: unreachable\n", report.From, report.To)
+		} else {
+			_, _ = fmt.Fprintf(bw, "path from %s to %s: %s\n", report.From, report.To, strings.Join(report.Path, " -> "))
+		}
+	}
+	_, _ = fmt.Fprintln(bw, "centrality (city in out closeness betweenness eccentricity):")
+	for _, c := range report.Centrality {
+		_, _ = fmt.Fprintf(bw, "\t%s %d %d %.4f %.4f %d\n", c.City, c.In, c.Out, c.Closeness, c.Betweenness, c.Eccentricity)
+	}
+	return bw.Flush()
+}
+
+// writeGroups writes the number of groups of Cities followed by the groups, one per line
+func writeGroups(w io.Writer, title string, groups [][]string) {
+	_, _ = fmt.Fprintf(w, "%s: %d\n", title, len(groups))
+	for _, group := range groups {
+		_, _ = fmt.Fprintf(w, "\t%s\n", strings.Join(group, ", "))
+	}
+}
+
+// joinOrNone joins the list of Cities, the empty list is written as none
+func joinOrNone(cities []string) string {
+	if len(cities) == 0 {
+		return "none"
+	}
+	return strings.Join(cities, ", ")
+}
+
+// nonNil replaces nil list with the empty one, so it is encoded as the empty JSON array
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// nonNilGroups replaces nil list of groups with the empty one, so it is encoded as the empty JSON array
+func nonNilGroups(groups [][]string) [][]string {
+	if groups == nil {
+		return [][]string{}
+	}
+	return groups
+}
@@ -0,0 +1,131 @@
+package infrastructure
+
+import (
+	"bytes"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/graph"
+	"github.com/zippunov/alien-invasion/internal/usecases"
+	"testing"
+)
+
+func Test_writeAnalysis(t *testing.T) {
+	report := usecases.AnalysisReport{
+		Cities:      2,
+		Roads:       1,
+		OneWayRoads: 1,
+		Inconsistencies: []domain.Inconsistency{
+			{Kind: domain.OneWayRoad, From: "Foo", To: "Bar", Direction: domain.North},
+		},
+		Diameter:          1,
+		StronglyConnected: [][]string{{"Bar"}, {"Foo"}},
+		WeaklyConnected:   [][]string{{"Bar", "Foo"}},
+		Sinks:             []string{"Bar"},
+		Traps:             [][]string{{"Bar"}},
+		Centrality: []graph.Centrality{
+			{City: "Bar", In: 1},
+			{City: "Foo", Out: 1, Closeness: 1, Eccentricity: 1},
+		},
+		From:      "Bar",
+		Reachable: nil,
+		To:        "Foo",
+	}
+	tests := []struct {
+		name   string
+		asJSON bool
+		want   string
+	}{
+		{
+			name: "Text",
+			want: `cities: 2
+roads: 1
+one-way roads: 1
+conflicting roads: 0
+	road Foo north=Bar has no reverse road Bar south=Foo
+diameter: 1
+strongly connected components: 2
+	Bar
+	Foo
+weakly connected components: 1
+	Bar, Foo
+sinks: Bar
+traps: 1
+	Bar
+cycle: none
+reachable from Bar: none
+path from Bar to Foo: unreachable
+centrality (city in out closeness betweenness eccentricity):
+	Bar 1 0 0.0000 0.0000 0
+	Foo 0 1 1.0000 0.0000 1
+`,
+		},
+		{
+			name:   "JSON",
+			asJSON: true,
+			want: `{
+  "cities": 2,
+  "roads": 1,
+  "one_way_roads": 1,
+  "conflicting_roads": 0,
+  "inconsistencies": [
+    "road Foo north=Bar has no reverse road Bar south=Foo"
+  ],
+  "diameter": 1,
+  "strongly_connected": [
+    [
+      "Bar"
+    ],
+    [
+      "Foo"
+    ]
+  ],
+  "weakly_connected": [
+    [
+      "Bar",
+      "Foo"
+    ]
+  ],
+  "sinks": [
+    "Bar"
+  ],
+  "traps": [
+    [
+      "Bar"
+    ]
+  ],
+  "cycle": [],
+  "centrality": [
+    {
+      "city": "Bar",
+      "in": 1,
+      "out": 0,
+      "closeness": 0,
+      "betweenness": 0,
+      "eccentricity": 0
+    },
+    {
+      "city": "Foo",
+      "in": 0,
+      "out": 1,
+      "closeness": 1,
+      "betweenness": 0,
+      "eccentricity": 1
+    }
+  ],
+  "from": "Bar",
+  "to": "Foo"
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeAnalysis(&buf, report, tt.asJSON); err != nil {
+				t.Fatalf("writeAnalysis() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeAnalysis() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/zippunov/alien-invasion/internal/usecases"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// jsonReport is JSON representation of the usecases.BatchReport
+type jsonReport struct {
+	Runs                    int                `json:"runs"`
+	Fights                  int                `json:"fights"`
+	MeanLastDestructionTick float64            `json:"mean_last_destruction_tick"`
+	DestructionProbability  map[string]float64 `json:"destruction_probability"`
+	Survivors               map[int]int        `json:"survivors"`
+}
+
+// WriteReport writes aggregated outcomes of the Batch into the output, CSV or JSON
+func (i *Infra) WriteReport(report usecases.BatchReport) error {
+	return writeReport(i.writer, report, i.reportJSON)
+}
+
+// writeReport writes aggregated outcomes of the Batch. CSV report is the list of metric,key,value rows:
+//
+//	metric,key,value
+//	runs,,1000
+//	fights,,998
+//	mean_last_destruction_tick,,57.3
+//	destruction_probability,Foo,0.25
+//	survivors,3,120
+func writeReport(w io.Writer, report usecases.BatchReport, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(jsonReport{
+			Runs:                    report.Runs,
+			Fights:                  report.Fights,
+			MeanLastDestructionTick: report.MeanLastDestructionTick,
+			DestructionProbability:  report.DestructionProbability,
+			Survivors:               report.Survivors,
+		})
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"metric", "key", "value"})
+	_ = cw.Write([]string{"runs", "", strconv.Itoa(report.Runs)})
+	_ = cw.Write([]string{"fights", "", strconv.Itoa(report.Fights)})
+	_ = cw.Write([]string{"mean_last_destruction_tick", "", formatFloat(report.MeanLastDestructionTick)})
+	cities := make([]string, 0, len(report.DestructionProbability))
+	for city := range report.DestructionProbability {
+		cities = append(cities, city)
+	}
+	sort.Strings(cities)
+	for _, city := range cities {
+		_ = cw.Write([]string{"destruction_probability", city, formatFloat(report.DestructionProbability[city])})
+	}
+	survivors := make([]int, 0, len(report.Survivors))
+	for n := range report.Survivors {
+		survivors = append(survivors, n)
+	}
+	sort.Ints(survivors)
+	for _, n := range survivors {
+		_ = cw.Write([]string{"survivors", strconv.Itoa(n), strconv.Itoa(report.Survivors[n])})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatFloat formats float with the shortest representation
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"bytes"
+	"github.com/zippunov/alien-invasion/internal/usecases"
+	"testing"
+)
+
+func Test_writeReport(t *testing.T) {
+	report := usecases.BatchReport{
+		Runs:                    4,
+		Fights:                  3,
+		MeanLastDestructionTick: 2.5,
+		DestructionProbability:  map[string]float64{"Foo": 0.75, "Bar": 0, "New York": 0.25},
+		Survivors:               map[int]int{2: 3, 3: 1},
+	}
+	tests := []struct {
+		name   string
+		asJSON bool
+		want   string
+	}{
+		{
+			name: "CSV",
+			want: `metric,key,value
+runs,,4
+fights,,3
+mean_last_destruction_tick,,2.5
+destruction_probability,Bar,0
+destruction_probability,Foo,0.75
+destruction_probability,New York,0.25
+survivors,2,3
+survivors,3,1
+`,
+		},
+		{
+			name:   "JSON",
+			asJSON: true,
+			want: `{
+  "runs": 4,
+  "fights": 3,
+  "mean_last_destruction_tick": 2.5,
+  "destruction_probability": {
+    "Bar": 0,
+    "Foo": 0.75,
+    "New York": 0.25
+  },
+  "survivors": {
+    "2": 3,
+    "3": 1
+  }
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeReport(&buf, report, tt.asJSON); err != nil {
+				t.Fatalf("writeReport() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("writeReport() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
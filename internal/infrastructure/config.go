@@ -3,47 +3,254 @@ package infrastructure
 import (
 	"errors"
 	"flag"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"github.com/zippunov/alien-invasion/internal/usecases"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 )
 
+// Enumeration of the application commands
+const (
+	CommandRun      = "run"      // runs the Alien Invasion scenario, default command
+	CommandValidate = "validate" // validates the map file and reports every problem found
+	CommandBatch    = "batch"    // runs many scenarios in parallel and reports aggregated outcomes
+	CommandAnalyze  = "analyze"  // reports connectivity, traps, cycles and centrality of the map graph
+)
+
+// commands is the set of all known application commands
+var commands = map[string]struct{}{
+	CommandRun:      {},
+	CommandValidate: {},
+	CommandBatch:    {},
+	CommandAnalyze:  {},
+}
+
 // Config is holder for the settings given with application parpams and provides default values
 type Config struct {
-	mapFilePath string
-	aliensCount int
-	outFilePath string
-	log         func(format string, a ...any)
-	Help        bool
+	mapFilePath        string
+	inFormat           encoding.Format
+	aliensCount        int
+	moveBudget         int
+	tickLimit          int
+	strategy           string
+	names              string
+	placement          usecases.Placement
+	populationFilePath string
+	outFilePath        string
+	outFormat          encoding.Format
+	eventsFilePath     string
+	dotFilePath        string
+	stats              bool
+	statsFormat        encoding.Format
+	symmetric          bool
+	simultaneous       bool
+	directions         string
+	seed               int64
+	scenario           encoding.ScenarioDefinition // optional hand-built scenario, see applyScenario
+	runs               int
+	workers            int
+	reportJSON         bool // batch and analyze report format, CSV or text otherwise
+	from               string
+	to                 string
+	log                func(format string, a ...any)
+	Command            string
+	Help               bool
 }
 
-// InitConfig validates application params and creates new Config instance
+// InitConfig validates application params and creates new Config instance.
+// The first param is the command name, unless it is a flag. Default command is CommandRun.
 func InitConfig(log func(format string, a ...any)) (Config, error) {
+	command, args := CommandRun, os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	if _, ok := commands[command]; !ok {
+		return Config{}, fmt.Errorf("unknown command %s", command)
+	}
 	var (
-		mapFile     string
-		aliensCount uint
-		outFile     string
-		help        bool
+		mapFile        string
+		aliensCount    uint
+		moveBudget     uint
+		tickLimit      uint
+		strategy       string
+		names          string
+		placement      string
+		populationFile string
+		outFile        string
+		format         string
+		eventsFile     string
+		dotFile        string
+		stats          string
+		symmetric      bool
+		simultaneous   bool
+		directions     string
+		seed           int64
+		scenarioFile   string
+		runs           uint
+		workers        uint
+		report         string
+		from           string
+		to             string
+		help           bool
 	)
 	flag.StringVar(&mapFile, "f", "", "")
 	flag.UintVar(&aliensCount, "n", 0, "")
+	flag.UintVar(&moveBudget, "moves", 10000, "")
+	flag.UintVar(&tickLimit, "ticks", 0, "")
+	flag.StringVar(&strategy, "strategy", "uniform", "")
+	flag.StringVar(&names, "names", "prefix:alien", "")
+	flag.StringVar(&placement, "placement", "unique", "")
+	flag.StringVar(&populationFile, "population", "", "")
 	flag.StringVar(&outFile, "o", "", "")
+	flag.StringVar(&format, "format", "", "")
+	flag.StringVar(&eventsFile, "events", "", "")
+	flag.StringVar(&dotFile, "dot", "", "")
+	flag.StringVar(&stats, "stats", "", "")
+	flag.BoolVar(&symmetric, "symmetric", false, "")
+	flag.BoolVar(&simultaneous, "simultaneous", false, "")
+	flag.StringVar(&directions, "directions", "compass4", "")
+	flag.Int64Var(&seed, "seed", 0, "")
+	flag.StringVar(&scenarioFile, "scenario", "", "")
+	flag.UintVar(&runs, "runs", 1000, "")
+	flag.UintVar(&workers, "workers", uint(runtime.NumCPU()), "")
+	flag.StringVar(&report, "report", "", "")
+	flag.StringVar(&from, "from", "", "")
+	flag.StringVar(&to, "to", "", "")
 	flag.BoolVar(&help, "h", false, "")
-	flag.Parse()
+	if err := flag.CommandLine.Parse(args); err != nil {
+		return Config{}, err
+	}
+	if command == CommandBatch {
+		// batch runs do not log events, render or summarize the single run
+		for _, name := range []string{"events", "dot", "stats"} {
+			if isFlagSet(name) {
+				return Config{}, fmt.Errorf("-%s is not supported by the %s command", name, command)
+			}
+		}
+	}
 
 	config := Config{
-		mapFilePath: mapFile,
-		aliensCount: int(aliensCount),
-		outFilePath: outFile,
-		log:         log,
-		Help:        help,
+		mapFilePath:        mapFile,
+		aliensCount:        int(aliensCount),
+		moveBudget:         int(moveBudget),
+		tickLimit:          int(tickLimit),
+		strategy:           strategy,
+		names:              names,
+		populationFilePath: populationFile,
+		outFilePath:        outFile,
+		eventsFilePath:     eventsFile,
+		dotFilePath:        dotFile,
+		symmetric:          symmetric,
+		simultaneous:       simultaneous,
+		directions:         directions,
+		seed:               seed,
+		runs:               int(runs),
+		workers:            int(workers),
+		from:               from,
+		to:                 to,
+		log:                log,
+		Command:            command,
+		Help:               help,
+	}
+	var ok bool
+	if config.placement, ok = usecases.PlacementByName(placement); !ok {
+		return Config{}, fmt.Errorf("unknown placement policy %s", placement)
+	}
+	if len(stats) != 0 {
+		f, ok := encoding.FormatByName(stats)
+		if !ok || f != encoding.Txt && f != encoding.JSON {
+			return Config{}, fmt.Errorf("unknown stats format %s", stats)
+		}
+		config.stats, config.statsFormat = true, f
+	}
+	if !isFlagSet("seed") {
+		config.seed = time.Now().UnixNano()
+	}
+	if len(scenarioFile) != 0 {
+		if command != CommandRun && command != CommandBatch {
+			return Config{}, fmt.Errorf("scenario file is not supported by the %s command", command)
+		}
+		if err := config.applyScenario(scenarioFile); err != nil {
+			return Config{}, err
+		}
+	}
+	if err := config.resolveFormats(format); err != nil {
+		return Config{}, err
+	}
+	if err := config.resolveReport(report); err != nil {
+		return Config{}, err
 	}
 
 	if !config.Help {
-		if len(mapFile) == 0 {
+		if len(config.mapFilePath) == 0 {
 			return Config{}, errors.New("missing map file path")
 		}
-		if aliensCount == 0 {
+		if config.aliensCount == 0 && (command == CommandRun || command == CommandBatch) {
 			return Config{}, errors.New("aliens number must be greater than 0")
 		}
 	}
 
 	return config, nil
 }
+
+// Seed returns the seed of the random source used by the scenario.
+// Either given with the -seed param or the scenario file, or generated from the current time.
+func (c Config) Seed() int64 {
+	return c.seed
+}
+
+// resolveFormats sets input and output Map formats. Explicitly given format is used for both.
+// Otherwise, formats are detected by the file extensions, the output format falls back to the input one.
+func (c *Config) resolveFormats(format string) error {
+	if len(format) != 0 {
+		f, ok := encoding.FormatByName(format)
+		if !ok {
+			return fmt.Errorf("unknown map format %s", format)
+		}
+		c.inFormat, c.outFormat = f, f
+		return nil
+	}
+	c.inFormat, _ = encoding.FormatByPath(c.mapFilePath)
+	c.outFormat = c.inFormat
+	if f, ok := encoding.FormatByPath(c.outFilePath); ok {
+		c.outFormat = f
+	}
+	return nil
+}
+
+// resolveReport sets the report format of the batch and analyze commands: json, or csv for the batch command
+// and txt for the analyze command. Otherwise, the format is detected by the output file extension.
+func (c *Config) resolveReport(report string) error {
+	if len(report) == 0 {
+		c.reportJSON = strings.EqualFold(filepath.Ext(c.outFilePath), ".json")
+		return nil
+	}
+	text := "csv"
+	if c.Command == CommandAnalyze {
+		text = "txt"
+	}
+	switch strings.ToLower(report) {
+	case text:
+		c.reportJSON = false
+	case "json":
+		c.reportJSON = true
+	default:
+		return fmt.Errorf("unknown report format %s", report)
+	}
+	return nil
+}
+
+// isFlagSet reports whether the flag with given name was explicitly passed to the application
+func isFlagSet(name string) bool {
+	found := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			found = true
+		}
+	})
+	return found
+}
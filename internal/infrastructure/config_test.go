@@ -0,0 +1,36 @@
+package infrastructure
+
+import (
+	"flag"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestInitConfig(t *testing.T) {
+	defer func(args []string, commandLine *flag.FlagSet) {
+		os.Args, flag.CommandLine = args, commandLine
+	}(os.Args, flag.CommandLine)
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{name: "Run", args: []string{"-f", "map.txt", "-n", "2", "-events", "events.jsonl", "-dot", "map.dot", "-stats", "txt"}},
+		{name: "Batch", args: []string{"batch", "-f", "map.txt", "-n", "2", "-runs", "10"}},
+		{name: "Batch with events", args: []string{"batch", "-f", "map.txt", "-n", "2", "-events", "events.jsonl"}, wantErr: true},
+		{name: "Batch with dot", args: []string{"batch", "-f", "map.txt", "-n", "2", "-dot", "map.dot"}, wantErr: true},
+		{name: "Batch with stats", args: []string{"batch", "-f", "map.txt", "-n", "2", "-stats", "json"}, wantErr: true},
+		{name: "Unknown command", args: []string{"fly", "-f", "map.txt"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = append([]string{"alien-invasion"}, tt.args...)
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			flag.CommandLine.SetOutput(io.Discard)
+			if _, err := InitConfig(func(string, ...any) {}); (err != nil) != tt.wantErr {
+				t.Errorf("InitConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
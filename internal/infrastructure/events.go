@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/usecases"
+	"io"
+)
+
+// Compile check to verify Interface Compliance.
+var _ usecases.Observer = (*jsonLinesLog)(nil)
+
+// jsonEvent is JSON representation of the usecases.Event
+type jsonEvent struct {
+	Event     string         `json:"event"`
+	Tick      int            `json:"tick"`
+	Alien     *domain.Alien  `json:"alien,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	City      string         `json:"city,omitempty"`
+	To        string         `json:"to,omitempty"`
+	Direction string         `json:"direction,omitempty"`
+	Aliens    []domain.Alien `json:"aliens,omitempty"`
+	Names     []string       `json:"names,omitempty"`
+}
+
+// jsonLinesLog is the Scenario Observer writing one JSON object per line for every Event.
+// Aliens are reported with their ids and names. Writing stops after the first failure,
+// the failure is reported with the log function.
+type jsonLinesLog struct {
+	w        *bufio.Writer
+	enc      *json.Encoder
+	registry *domain.AlienRegistry
+	err      error
+	log      func(format string, a ...any)
+}
+
+// newJSONLinesLog creates JSON Lines Observer on top of the given io.Writer
+func newJSONLinesLog(w io.Writer, registry *domain.AlienRegistry, log func(format string, a ...any)) *jsonLinesLog {
+	bw := bufio.NewWriter(w)
+	return &jsonLinesLog{
+		w:        bw,
+		enc:      json.NewEncoder(bw),
+		registry: registry,
+		log:      log,
+	}
+}
+
+// Notify is the usecases.Observer interface implementation
+func (l *jsonLinesLog) Notify(e usecases.Event) {
+	if l.err != nil {
+		return
+	}
+	je := jsonEvent{
+		Event:  e.Kind.String(),
+		Tick:   e.Tick,
+		City:   e.City,
+		To:     e.To,
+		Aliens: e.Aliens,
+	}
+	if e.Kind != usecases.ScenarioFinished {
+		alien := e.Alien
+		je.Alien = &alien
+		je.Name = l.registry.Name(alien)
+	}
+	for _, alien := range e.Aliens {
+		je.Names = append(je.Names, l.registry.Name(alien))
+	}
+	if e.Kind == usecases.AlienMoved || e.Kind == usecases.RoadDestroyed {
+		je.Direction = e.Direction.String()
+	}
+	if l.err = l.enc.Encode(je); l.err != nil {
+		l.log("events log: %v\n", l.err)
+	}
+}
+
+// Flush writes buffered events to the underlying io.Writer
+func (l *jsonLinesLog) Flush() {
+	if l.err != nil {
+		return
+	}
+	if l.err = l.w.Flush(); l.err != nil {
+		l.log("events log: %v\n", l.err)
+	}
+}
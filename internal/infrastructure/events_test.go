@@ -0,0 +1,57 @@
+package infrastructure
+
+import (
+	"bytes"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/usecases"
+	"testing"
+)
+
+func Test_jsonLinesLog_Notify(t *testing.T) {
+	tests := []struct {
+		name  string
+		event usecases.Event
+		want  string
+	}{
+		{
+			name:  "Seed",
+			event: usecases.Event{Kind: usecases.AlienSeeded, Alien: 0, City: "Foo"},
+			want:  `{"event":"seed","tick":0,"alien":0,"name":"Zorg","city":"Foo"}` + "\n",
+		},
+		{
+			name:  "Move",
+			event: usecases.Event{Kind: usecases.AlienMoved, Tick: 3, Alien: 2, City: "Bar", Direction: domain.West},
+			want:  `{"event":"move","tick":3,"alien":2,"name":"Big Bob","city":"Bar","direction":"west"}` + "\n",
+		},
+		{
+			name:  "Destruction",
+			event: usecases.Event{Kind: usecases.CityDestroyed, Tick: 4, Alien: 1, City: "Bar", Aliens: []domain.Alien{2, 1}},
+			want:  `{"event":"destruction","tick":4,"alien":1,"name":"Kang","city":"Bar","aliens":[2,1],"names":["Big Bob","Kang"]}` + "\n",
+		},
+		{
+			name:  "Road destruction",
+			event: usecases.Event{Kind: usecases.RoadDestroyed, Tick: 6, Alien: 0, City: "Foo", To: "Bar", Direction: domain.North, Aliens: []domain.Alien{0, 3}},
+			want:  `{"event":"road-destruction","tick":6,"alien":0,"name":"Zorg","city":"Foo","to":"Bar","direction":"north","aliens":[0,3],"names":["Zorg","Xi"]}` + "\n",
+		},
+		{
+			name:  "Finish",
+			event: usecases.Event{Kind: usecases.ScenarioFinished, Tick: 9},
+			want:  `{"event":"finish","tick":9}` + "\n",
+		},
+	}
+	registry, err := domain.NewAlienRegistry([]string{"Zorg", "Kang", "Big Bob", "Xi"})
+	if err != nil {
+		t.Fatalf("NewAlienRegistry() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			l := newJSONLinesLog(w, registry, func(format string, a ...any) {})
+			l.Notify(tt.event)
+			l.Flush()
+			if got := w.String(); got != tt.want {
+				t.Errorf("Notify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
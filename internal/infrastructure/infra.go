@@ -1,28 +1,66 @@
 package infrastructure
 
 import (
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/encoding"
 	"github.com/zippunov/alien-invasion/internal/usecases"
 	"io"
+	"math/rand"
 	"os"
 )
 
 // Compile check to verify Interface Compliance. See
 // https://github.com/uber-go/guide/blob/master/style.md#verify-interface-compliance
 var _ usecases.IInfra = (*Infra)(nil)
+var _ usecases.IValidationInfra = (*Infra)(nil)
+var _ usecases.IBatchInfra = (*Infra)(nil)
+var _ usecases.IAnalysisInfra = (*Infra)(nil)
 
 // Infra holds references to all external sources required by the application and
 // required configuration parameters
 type Infra struct {
 	reader      io.ReadCloser
+	inFormat    encoding.Format
 	aliensCount int
+	moveBudget  int
+	budgets     map[domain.Alien]int // per-Alien move budgets overriding the moveBudget
+	tickLimit   int
+	strategy    string                  // spec of the movement strategy, see usecases.NewStrategy
+	strategies  map[domain.Alien]string // per-Alien strategy specs overriding the strategy
+	starts      map[domain.Alien]string // per-Alien starting cities
+	placement   usecases.Placement
+	registry    *domain.AlienRegistry
+	population  map[string]int // population of the cities, cities without population weigh 1
 	writer      io.WriteCloser
+	outFormat   encoding.Format
+	symmetric   bool
+	rounds      bool
 	log         func(format string, a ...any)
+	rnd         *rand.Rand
+	eventsFile  io.WriteCloser // optional JSON Lines events log destination
+	eventsLog   *jsonLinesLog
+	dotFile     io.WriteCloser // optional Graphviz graphs destination
+	statsOut    io.Writer      // optional destination of the run summary
+	statsFormat encoding.Format
+	runs        int
+	workers     int
+	reportJSON  bool
+	from        string
+	to          string
 }
 
 // Shutdown does clean up at the end of application work.
 func (i *Infra) Shutdown() {
 	_ = i.reader.Close()
 	_ = i.writer.Close()
+	if i.eventsFile != nil {
+		i.eventsLog.Flush()
+		_ = i.eventsFile.Close()
+	}
+	if i.dotFile != nil {
+		_ = i.dotFile.Close()
+	}
 }
 
 // In is a part of usecases.IInfra interface implementation
@@ -30,24 +68,143 @@ func (i *Infra) In() io.Reader {
 	return i.reader
 }
 
+// InFormat is a part of usecases.IInfra interface implementation
+func (i *Infra) InFormat() encoding.Format {
+	return i.inFormat
+}
+
+// Simultaneous is a part of usecases.IInfra interface implementation
+func (i *Infra) Simultaneous() bool {
+	return i.rounds
+}
+
 // Out is a part of usecases.IInfra interface implementation
 func (i *Infra) Out() io.Writer {
 	return i.writer
 }
 
+// OutFormat is a part of usecases.IInfra interface implementation
+func (i *Infra) OutFormat() encoding.Format {
+	return i.outFormat
+}
+
 // AliensCount is a part of usecases.IInfra interface implementation
 func (i *Infra) AliensCount() int {
 	return i.aliensCount
 }
 
-// Log is a part of usecases.IInfra interface implementation
-func (i *Infra) Log() func(format string, a ...any) {
-	return i.log
+// MoveBudget is a part of usecases.IInfra interface implementation
+func (i *Infra) MoveBudget(alien domain.Alien) int {
+	if budget, ok := i.budgets[alien]; ok {
+		return budget
+	}
+	return i.moveBudget
+}
+
+// TickLimit is a part of usecases.IInfra interface implementation
+func (i *Infra) TickLimit() int {
+	return i.tickLimit
+}
+
+// Strategy is a part of usecases.IInfra interface implementation.
+// Every call creates new strategy instance. Specs are validated by InitInfra.
+func (i *Infra) Strategy(alien domain.Alien) usecases.MovementStrategy {
+	spec, ok := i.strategies[alien]
+	if !ok {
+		spec = i.strategy
+	}
+	strategy, _ := usecases.NewStrategy(spec)
+	return strategy
+}
+
+// StartCity is a part of usecases.IInfra interface implementation
+func (i *Infra) StartCity(alien domain.Alien) string {
+	return i.starts[alien]
+}
+
+// Placement is a part of usecases.IInfra interface implementation
+func (i *Infra) Placement() usecases.Placement {
+	return i.placement
+}
+
+// Population is a part of usecases.IInfra interface implementation.
+// Cities missing in the population file have population 1.
+func (i *Infra) Population(city string) int {
+	if n, ok := i.population[city]; ok {
+		return n
+	}
+	return 1
+}
+
+// Symmetric is a part of usecases.IInfra interface implementation
+func (i *Infra) Symmetric() bool {
+	return i.symmetric
+}
+
+// Dot is a part of usecases.IInfra interface implementation
+func (i *Infra) Dot() io.Writer {
+	if i.dotFile == nil {
+		return nil
+	}
+	return i.dotFile
+}
+
+// Observers is a part of usecases.IInfra interface implementation.
+// Destroyed cities are reported with the application log function.
+// Every event is written into the events log if it is configured.
+func (i *Infra) Observers() []usecases.Observer {
+	observers := []usecases.Observer{usecases.DestructionLog(i.log, i.registry)}
+	if i.eventsLog != nil {
+		observers = append(observers, i.eventsLog)
+	}
+	return observers
+}
+
+// Registry is a part of usecases.IInfra interface implementation
+func (i *Infra) Registry() *domain.AlienRegistry {
+	return i.registry
+}
+
+// Runs is a part of usecases.IBatchInfra interface implementation
+func (i *Infra) Runs() int {
+	return i.runs
+}
+
+// Workers is a part of usecases.IBatchInfra interface implementation
+func (i *Infra) Workers() int {
+	return i.workers
+}
+
+// From is a part of usecases.IAnalysisInfra interface implementation
+func (i *Infra) From() string {
+	return i.from
+}
+
+// To is a part of usecases.IAnalysisInfra interface implementation
+func (i *Infra) To() string {
+	return i.to
+}
+
+// Rand is a part of usecases.IInfra interface implementation
+func (i *Infra) Rand() *rand.Rand {
+	return i.rnd
 }
 
 // InitInfra does initialization of the all application external resources
-// according to given configuration
+// according to given configuration. Configured direction set becomes active.
 func InitInfra(config Config) (Infra, error) {
+	directions, err := encoding.LoadDirections(config.directions)
+	if err != nil {
+		return Infra{}, err
+	}
+	domain.UseDirections(directions)
+	if err := validateStrategies(config); err != nil {
+		return Infra{}, err
+	}
+	registry, err := BuildRegistry(config.names, config.aliensCount, config.seed, scenarioNames(config.scenario.Aliens))
+	if err != nil {
+		return Infra{}, err
+	}
 	inFile, err := os.Open(config.mapFilePath)
 	if err != nil {
 		return Infra{}, err
@@ -61,10 +218,76 @@ func InitInfra(config Config) (Infra, error) {
 	if outFile == nil {
 		outFile = os.Stdout
 	}
-	return Infra{
+	infra := Infra{
 		reader:      inFile,
+		inFormat:    config.inFormat,
 		aliensCount: config.aliensCount,
+		moveBudget:  config.moveBudget,
+		tickLimit:   config.tickLimit,
+		strategy:    config.strategy,
+		placement:   config.placement,
+		registry:    registry,
+		statsFormat: config.statsFormat,
+		runs:        config.runs,
+		workers:     config.workers,
+		reportJSON:  config.reportJSON,
+		from:        config.from,
+		to:          config.to,
 		writer:      outFile,
+		outFormat:   config.outFormat,
+		symmetric:   config.symmetric,
+		rounds:      config.simultaneous,
 		log:         config.log,
-	}, nil
+		rnd:         rand.New(rand.NewSource(config.seed)),
+	}
+	infra.scenarioAliens(config.scenario.Aliens)
+	if len(config.populationFilePath) != 0 {
+		if infra.population, err = LoadPopulation(config.populationFilePath); err != nil {
+			return Infra{}, err
+		}
+	}
+	if len(config.eventsFilePath) != 0 {
+		eventsFile, err := os.Create(config.eventsFilePath)
+		if err != nil {
+			return Infra{}, err
+		}
+		infra.eventsFile = eventsFile
+		infra.eventsLog = newJSONLinesLog(eventsFile, registry, config.log)
+	}
+	if config.stats {
+		infra.statsOut = os.Stderr
+	}
+	if len(config.dotFilePath) != 0 {
+		if infra.dotFile, err = os.Create(config.dotFilePath); err != nil {
+			return Infra{}, err
+		}
+	}
+	return infra, nil
+}
+
+// validateStrategies ensures that every movement strategy spec of the Config is valid.
+// Weights of the roads refer to directions, so the direction set must be active.
+func validateStrategies(config Config) error {
+	if _, err := usecases.NewStrategy(config.strategy); err != nil {
+		return err
+	}
+	for n, def := range config.scenario.Aliens {
+		if len(def.Strategy) == 0 {
+			continue
+		}
+		if _, err := usecases.NewStrategy(def.Strategy); err != nil {
+			return fmt.Errorf("alien %d: %v", n, err)
+		}
+	}
+	return nil
+}
+
+// LoadPopulation reads population of the cities from the file with the given path
+func LoadPopulation(path string) (map[string]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return encoding.UnmarshalPopulation(f)
 }
@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"github.com/zippunov/alien-invasion/internal/names"
+	"math/rand"
+	"os"
+	"strings"
+)
+
+// alienSyllables build the generated alien names
+var alienSyllables = names.Syllables{
+	Onsets:  []string{"b", "br", "d", "dr", "g", "gl", "k", "kr", "m", "n", "p", "qu", "r", "s", "sk", "t", "th", "v", "x", "z", "zh"},
+	Vowels:  []string{"a", "e", "i", "o", "u", "aa", "ee", "oo", "y"},
+	Endings: []string{"", "", "", "k", "x", "n", "r", "th", "sh", "g", "z"},
+}
+
+// BuildRegistry names n aliens according to the names spec:
+//
+//	prefix:<PREFIX>   prefix followed by the alien number counting from 1, e.g. "alien 1"
+//	generate          random pronounceable names, the same seed gives the same names
+//	<PATH>            names read from the file, one name per line
+//
+// Non-empty fixed[i] overrides the name of the alien i.
+func BuildRegistry(spec string, n int, seed int64, fixed []string) (*domain.AlienRegistry, error) {
+	var names []string
+	switch {
+	case strings.HasPrefix(spec, "prefix:"):
+		names = prefixNames(strings.TrimPrefix(spec, "prefix:"), n)
+	case spec == "generate":
+		names = alienSyllables.GenerateNames(n, rand.New(rand.NewSource(seed)))
+	default:
+		var err error
+		if names, err = loadNames(spec); err != nil {
+			return nil, err
+		}
+		if len(names) < n {
+			return nil, fmt.Errorf("names file %s has %d names, but aliens number is %d", spec, len(names), n)
+		}
+		names = names[:n]
+	}
+	for i, name := range fixed {
+		if len(name) != 0 && i < n {
+			names[i] = name
+		}
+	}
+	return domain.NewAlienRegistry(names)
+}
+
+// prefixNames names aliens with the prefix and the alien number counting from 1
+func prefixNames(prefix string, n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s %d", prefix, i+1)
+	}
+	return names
+}
+
+// loadNames reads names from the file with the given path
+func loadNames(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return encoding.UnmarshalNames(f)
+}
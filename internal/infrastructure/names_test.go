@@ -0,0 +1,67 @@
+package infrastructure
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.txt")
+	if err := os.WriteFile(path, []byte("# crew\nZorg\nKang\nBig Bob\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		spec    string
+		n       int
+		fixed   []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Prefix", spec: "prefix:alien", n: 3, want: []string{"alien 1", "alien 2", "alien 3"}},
+		{name: "Prefix with fixed", spec: "prefix:ufo", n: 3, fixed: []string{"", "Kang"}, want: []string{"ufo 1", "Kang", "ufo 3"}},
+		{name: "File", spec: path, n: 2, want: []string{"Zorg", "Kang"}},
+		{name: "File is too short", spec: path, n: 4, wantErr: true},
+		{name: "Missing file", spec: path + ".missing", n: 1, wantErr: true},
+		{name: "Duplicate fixed name", spec: path, n: 3, fixed: []string{"", "", "Zorg"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := BuildRegistry(tt.spec, tt.n, 1, tt.fixed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			for i, name := range tt.want {
+				if got := r.Name(domain.Alien(i)); got != name {
+					t.Errorf("Name(%d) = %s, want %s", i, got, name)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildRegistry_Generate(t *testing.T) {
+	a, err := BuildRegistry("generate", 1000, 42, nil)
+	if err != nil {
+		t.Fatalf("BuildRegistry() error = %v", err)
+	}
+	b, _ := BuildRegistry("generate", 1000, 42, nil)
+	c, _ := BuildRegistry("generate", 1000, 43, nil)
+	same, differ := true, false
+	for i := 0; i < 1000; i++ {
+		same = same && a.Name(domain.Alien(i)) == b.Name(domain.Alien(i))
+		differ = differ || a.Name(domain.Alien(i)) != c.Name(domain.Alien(i))
+		if name := a.Name(domain.Alien(i)); strings.ContainsAny(name, " 0123456789") {
+			t.Errorf("Name(%d) = %s, generated names must be single words without digits", i, name)
+		}
+	}
+	if !same || !differ {
+		t.Errorf("generated names must depend on the seed only")
+	}
+}
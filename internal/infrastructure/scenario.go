@@ -0,0 +1,76 @@
+package infrastructure
+
+import (
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"os"
+	"path/filepath"
+)
+
+// applyScenario reads the scenario file and applies its settings. Settings given with the application params
+// take precedence over the scenario ones. Map path of the scenario is relative to the scenario file.
+// Number of aliens defaults to the number of scenario aliens, extra aliens start in random cities.
+func (c *Config) applyScenario(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	format, _ := encoding.FormatByPath(path)
+	def, err := encoding.UnmarshalScenario(f, format)
+	if err != nil {
+		return fmt.Errorf("scenario %s: %v", path, err)
+	}
+	if len(def.Map) != 0 && !isFlagSet("f") {
+		c.mapFilePath = def.Map
+		if !filepath.IsAbs(def.Map) {
+			c.mapFilePath = filepath.Join(filepath.Dir(path), def.Map)
+		}
+	}
+	if def.Seed != nil && !isFlagSet("seed") {
+		c.seed = *def.Seed
+	}
+	if def.Moves != nil && !isFlagSet("moves") {
+		c.moveBudget = *def.Moves
+	}
+	if len(def.Strategy) != 0 && !isFlagSet("strategy") {
+		c.strategy = def.Strategy
+	}
+	if !isFlagSet("n") {
+		c.aliensCount = len(def.Aliens)
+	}
+	if c.aliensCount < len(def.Aliens) {
+		return fmt.Errorf("scenario %s defines %d aliens, but aliens number is %d", path, len(def.Aliens), c.aliensCount)
+	}
+	c.scenario = def
+	return nil
+}
+
+// scenarioAliens converts scenario Alien definitions into per-Alien settings of the Infra
+func (i *Infra) scenarioAliens(aliens []encoding.AlienDefinition) {
+	i.budgets = map[domain.Alien]int{}
+	i.strategies = map[domain.Alien]string{}
+	i.starts = map[domain.Alien]string{}
+	for n, def := range aliens {
+		alien := domain.Alien(n)
+		if def.Moves != nil {
+			i.budgets[alien] = *def.Moves
+		}
+		if len(def.Strategy) != 0 {
+			i.strategies[alien] = def.Strategy
+		}
+		if len(def.City) != 0 {
+			i.starts[alien] = def.City
+		}
+	}
+}
+
+// scenarioNames lists names of the scenario Aliens, empty string for the Alien without name
+func scenarioNames(aliens []encoding.AlienDefinition) []string {
+	names := make([]string, len(aliens))
+	for i, def := range aliens {
+		names[i] = def.Name
+	}
+	return names
+}
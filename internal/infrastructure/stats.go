@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"github.com/zippunov/alien-invasion/internal/usecases"
+	"io"
+	"sort"
+)
+
+// jsonStats is JSON representation of the usecases.Stats
+type jsonStats struct {
+	CitiesDestroyed     int            `json:"cities_destroyed"`
+	CitiesSurvived      int            `json:"cities_survived"`
+	RoadsDestroyed      int            `json:"roads_destroyed"`
+	AliensKilled        int            `json:"aliens_killed"`
+	AliensTrapped       int            `json:"aliens_trapped"`
+	AliensExhausted     int            `json:"aliens_exhausted"`
+	AliensSurvived      int            `json:"aliens_survived"`
+	TotalMoves          int            `json:"total_moves"`
+	Ticks               int            `json:"ticks"`
+	LastDestructionTick int            `json:"last_destruction_tick"`
+	LargestComponent    int            `json:"largest_component"`
+	Destroyed           []string       `json:"destroyed"`
+	Visits              map[string]int `json:"visits"`
+}
+
+// WriteStats writes summary of the Scenario run if it is requested with the -stats param
+func (i *Infra) WriteStats(stats usecases.Stats) error {
+	if i.statsOut == nil {
+		return nil
+	}
+	return writeStats(i.statsOut, stats, i.statsFormat)
+}
+
+// writeStats writes summary of the Scenario run in the given format, txt or json
+func writeStats(w io.Writer, stats usecases.Stats, f encoding.Format) error {
+	if f == encoding.JSON {
+		destroyed := stats.Destroyed
+		if destroyed == nil {
+			destroyed = []string{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(jsonStats{
+			CitiesDestroyed:     stats.CitiesDestroyed,
+			CitiesSurvived:      stats.CitiesSurvived,
+			RoadsDestroyed:      stats.RoadsDestroyed,
+			AliensKilled:        stats.AliensKilled,
+			AliensTrapped:       stats.AliensTrapped,
+			AliensExhausted:     stats.AliensExhausted,
+			AliensSurvived:      stats.AliensSurvived,
+			TotalMoves:          stats.TotalMoves,
+			Ticks:               stats.Ticks,
+			LastDestructionTick: stats.LastDestructionTick,
+			LargestComponent:    stats.LargestComponent,
+			Destroyed:           destroyed,
+			Visits:              stats.Visits,
+		})
+	}
+	bw := bufio.NewWriter(w)
+	_, _ = fmt.Fprintf(bw, "cities destroyed: %d\n", stats.CitiesDestroyed)
+	_, _ = fmt.Fprintf(bw, "cities survived: %d\n", stats.CitiesSurvived)
+	_, _ = fmt.Fprintf(bw, "roads destroyed: %d\n", stats.RoadsDestroyed)
+	_, _ = fmt.Fprintf(bw, "aliens killed: %d\n", stats.AliensKilled)
+	_, _ = fmt.Fprintf(bw, "aliens trapped: %d\n", stats.AliensTrapped)
+	_, _ = fmt.Fprintf(bw, "aliens exhausted: %d\n", stats.AliensExhausted)
+	_, _ = fmt.Fprintf(bw, "aliens survived: %d\n", stats.AliensSurvived)
+	_, _ = fmt.Fprintf(bw, "total moves: %d\n", stats.TotalMoves)
+	_, _ = fmt.Fprintf(bw, "ticks: %d\n", stats.Ticks)
+	_, _ = fmt.Fprintf(bw, "last destruction tick: %d\n", stats.LastDestructionTick)
+	_, _ = fmt.Fprintf(bw, "largest surviving component: %d\n", stats.LargestComponent)
+	_, _ = fmt.Fprintln(bw, "visits:")
+	cities := make([]string, 0, len(stats.Visits))
+	for city := range stats.Visits {
+		cities = append(cities, city)
+	}
+	sort.Strings(cities)
+	for _, city := range cities {
+		_, _ = fmt.Fprintf(bw, "\t%s %d\n", city, stats.Visits[city])
+	}
+	return bw.Flush()
+}
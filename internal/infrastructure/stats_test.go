@@ -0,0 +1,87 @@
+package infrastructure
+
+import (
+	"bytes"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"github.com/zippunov/alien-invasion/internal/usecases"
+	"testing"
+)
+
+func Test_writeStats(t *testing.T) {
+	stats := usecases.Stats{
+		CitiesDestroyed:     1,
+		CitiesSurvived:      2,
+		AliensKilled:        2,
+		AliensExhausted:     1,
+		AliensSurvived:      1,
+		TotalMoves:          5,
+		Ticks:               5,
+		LastDestructionTick: 3,
+		LargestComponent:    2,
+		Destroyed:           []string{"Foo"},
+		Visits:              map[string]int{"Foo": 3, "Bar": 4, "Baz": 1},
+	}
+	tests := []struct {
+		name   string
+		format encoding.Format
+		want   string
+	}{
+		{
+			name:   "Text",
+			format: encoding.Txt,
+			want: `cities destroyed: 1
+cities survived: 2
+roads destroyed: 0
+aliens killed: 2
+aliens trapped: 0
+aliens exhausted: 1
+aliens survived: 1
+total moves: 5
+ticks: 5
+last destruction tick: 3
+largest surviving component: 2
+visits:
+	Bar 4
+	Baz 1
+	Foo 3
+`,
+		},
+		{
+			name:   "JSON",
+			format: encoding.JSON,
+			want: `{
+  "cities_destroyed": 1,
+  "cities_survived": 2,
+  "roads_destroyed": 0,
+  "aliens_killed": 2,
+  "aliens_trapped": 0,
+  "aliens_exhausted": 1,
+  "aliens_survived": 1,
+  "total_moves": 5,
+  "ticks": 5,
+  "last_destruction_tick": 3,
+  "largest_component": 2,
+  "destroyed": [
+    "Foo"
+  ],
+  "visits": {
+    "Bar": 4,
+    "Baz": 1,
+    "Foo": 3
+  }
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			if err := writeStats(w, stats, tt.format); err != nil {
+				t.Fatalf("writeStats() error = %v", err)
+			}
+			if got := w.String(); got != tt.want {
+				t.Errorf("writeStats() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
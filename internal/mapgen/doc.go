@@ -0,0 +1,5 @@
+/*
+Package mapgen builds random World Maps for the alien-invasion application: names of the Cities
+and roads linking them.
+*/
+package mapgen
@@ -0,0 +1,129 @@
+package mapgen
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// Version of the generators. It is incremented whenever the same Manifest produces a different Map,
+// so the Map is reproduced exactly by the generator of the version recorded in its Manifest.
+// Generators of the previous versions are kept, so the Maps of the older Manifests are still reproduced:
+//
+//	1  links rejected by LinkCities are skipped, Cities left without roads are dropped
+//	2  every City is kept, Cities left without out-roads are repaired
+const Version = 2
+
+// legacyVersion is the first generator version, which does not repair Cities without out-roads
+const legacyVersion = 1
+
+// headerPrefix starts the manifest comment line of the Map text format
+const headerPrefix = "# mapgen "
+
+// Manifest records the generator version, topology, parameters and seed of the generated Map.
+// Parameters unused by the topology are left zero.
+type Manifest struct {
+	Version    int     `json:"version"`
+	Cities     int     `json:"cities"`
+	Names      string  `json:"names"`
+	NoDigits   bool    `json:"no_digits,omitempty"`
+	Directions string  `json:"directions"`
+	Topology   string  `json:"topology"`
+	Width      int     `json:"width,omitempty"`
+	OneWay     bool    `json:"one_way,omitempty"`
+	Remove     float64 `json:"remove,omitempty"`
+	P          float64 `json:"p,omitempty"`
+	M          int     `json:"m,omitempty"`
+	K          int     `json:"k,omitempty"`
+	Beta       float64 `json:"beta,omitempty"`
+	Seed       int64   `json:"seed"`
+}
+
+// Generate builds the Map described by the Manifest. Every random choice is drawn from the source seeded
+// with the Manifest seed, so the same Manifest always produces the same Map.
+// Directions of the Manifest must be activated by the caller.
+func Generate(mf Manifest) (domain.Map, error) {
+	if mf.Version < legacyVersion || mf.Version > Version {
+		return nil, fmt.Errorf("manifest version %d is not supported by the generator version %d", mf.Version, Version)
+	}
+	if mf.Cities <= 0 {
+		return nil, errors.New("missing number of cities")
+	}
+	t, ok := TopologyByName(mf.Topology)
+	if !ok {
+		return nil, fmt.Errorf("unknown topology %s", mf.Topology)
+	}
+	rnd := rand.New(rand.NewSource(mf.Seed))
+	names, err := Names(mf.Names, mf.Cities, mf.NoDigits, rnd)
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case TopologyRandom:
+		return Random(names, rnd)
+	case TopologyErdosRenyi, TopologyBarabasiAlbert, TopologyWattsStrogatz, TopologyStrong:
+		opts := ModelOptions{P: mf.P, M: mf.M, K: mf.K, Beta: mf.Beta, OneWay: mf.OneWay, Version: mf.Version}
+		return Model(t, names, opts, rnd)
+	default:
+		if mf.Remove < 0 || mf.Remove > 1 {
+			return nil, fmt.Errorf("invalid removal probability %v", mf.Remove)
+		}
+		opts := LatticeOptions{Width: mf.Width, OneWay: mf.OneWay, Remove: mf.Remove, Version: mf.Version}
+		return Lattice(t, names, opts, rnd)
+	}
+}
+
+// Header returns the Manifest as the comment line of the Map text format
+func (mf Manifest) Header() string {
+	data, _ := json.Marshal(mf)
+	return headerPrefix + string(data)
+}
+
+// WriteJSON writes the Manifest as the sidecar JSON file
+func (mf Manifest) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(mf)
+}
+
+// ReadManifest reads the Manifest either from the sidecar JSON file, or from the header
+// of the Map text file written by the generator. Reports whether the Manifest was read from the header.
+func ReadManifest(r io.Reader) (Manifest, bool, error) {
+	var mf Manifest
+	br := bufio.NewReader(r)
+	src := io.Reader(br)
+	prefix, _ := br.Peek(len(headerPrefix))
+	header := string(prefix) == headerPrefix
+	if header {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return mf, header, err
+		}
+		src = strings.NewReader(strings.TrimPrefix(line, headerPrefix))
+	}
+	if err := json.NewDecoder(src).Decode(&mf); err != nil {
+		return mf, header, fmt.Errorf("invalid manifest: %v", err)
+	}
+	return mf, header, nil
+}
+
+// Unchanged reads the Map text with the manifest header and ensures it still holds the Map generated
+// from the header. The Map changed after the generation, for example by the invasion, keeps the header
+// of the original one, so replaying its header would silently produce another Map.
+func Unchanged(r io.Reader, m domain.Map) error {
+	loaded := domain.Map{}
+	if err := encoding.UnmarshalTxt(r, loaded); err != nil {
+		return err
+	}
+	if diff := m.Diff(loaded); len(diff) != 0 {
+		return fmt.Errorf("map was changed after the generation, its manifest header does not describe it: "+
+			"%d differences, the first one is %s", len(diff), diff[0])
+	}
+	return nil
+}
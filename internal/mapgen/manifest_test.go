@@ -0,0 +1,153 @@
+package mapgen
+
+import (
+	"bytes"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mf      Manifest
+		wantErr bool
+	}{
+		{name: "Random", mf: Manifest{Version: Version, Cities: 100, Names: "sequential", Topology: "random", Seed: 7}},
+		{name: "Grid", mf: Manifest{Version: Version, Cities: 100, Names: "syllable", Topology: "grid", Remove: 0.2, Seed: 7}},
+		{name: "Model", mf: Manifest{Version: Version, Cities: 100, Names: "sequential", Topology: "barabasi-albert", M: 2, Seed: 7}},
+		{name: "Legacy version", mf: Manifest{Version: 1, Cities: 100, Names: "sequential", Topology: "grid", Remove: 0.2, Seed: 7}},
+		{name: "Unsupported version", mf: Manifest{Version: Version + 1, Cities: 10, Names: "letters", Topology: "random"}, wantErr: true},
+		{name: "Zero version", mf: Manifest{Cities: 10, Names: "letters", Topology: "random"}, wantErr: true},
+		{name: "Missing cities", mf: Manifest{Version: Version, Names: "letters", Topology: "random"}, wantErr: true},
+		{name: "Unknown topology", mf: Manifest{Version: Version, Cities: 10, Names: "letters", Topology: "maze"}, wantErr: true},
+		{name: "Invalid removal", mf: Manifest{Version: Version, Cities: 10, Names: "letters", Topology: "ring", Remove: 2}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Generate(tt.mf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			again, _ := Generate(tt.mf)
+			if diff := got.Diff(again); len(diff) != 0 {
+				t.Errorf("Generate() is not reproducible: %v", diff)
+			}
+			other := tt.mf
+			other.Seed++
+			if another, _ := Generate(other); got.Equal(another) {
+				t.Errorf("Generate() ignores the seed")
+			}
+		})
+	}
+}
+
+func TestGenerate_legacy(t *testing.T) {
+	// maps written by the generator version 1, the isolated city of the erdos-renyi map is dropped
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "Grid",
+			src: `# mapgen {"version":1,"cities":9,"names":"letters","directions":"compass4","topology":"grid","remove":0.4,"seed":5}
+C north=P south=L
+E west=Z south=W
+I north=Z south=Y
+L north=C
+P east=Z south=C
+V north=W west=Y
+W north=E south=V
+Y north=I east=V
+Z west=P east=E south=I
+`,
+		},
+		{
+			name: "Erdos-Renyi",
+			src: `# mapgen {"version":1,"cities":8,"names":"letters","directions":"compass4","topology":"erdos-renyi","p":0.2,"seed":3}
+A east=B
+B west=A north=V
+C east=V south=D
+D east=Y west=K north=C
+K north=Y east=D
+V south=B west=C
+Y south=K west=D
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mf, _, err := ReadManifest(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatalf("ReadManifest() error = %v", err)
+			}
+			m, err := Generate(mf)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if err := Unchanged(strings.NewReader(tt.src), m); err != nil {
+				t.Errorf("Generate() does not reproduce the version 1 map: %v", err)
+			}
+		})
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	mf := Manifest{Version: Version, Cities: 10, Names: "letters", Directions: "compass4", Topology: "watts-strogatz", K: 2, Beta: 0.1, Seed: 42}
+	var sidecar bytes.Buffer
+	if err := mf.WriteJSON(&sidecar); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		src        string
+		want       Manifest
+		wantHeader bool
+		wantErr    bool
+	}{
+		{name: "Sidecar", src: sidecar.String(), want: mf},
+		{name: "Header", src: mf.Header() + "\nA east=B\nB west=A\n", want: mf, wantHeader: true},
+		{name: "Header only", src: mf.Header(), want: mf, wantHeader: true},
+		{name: "Map without header", src: "# the world\nA east=B\n", wantErr: true},
+		{name: "Empty", src: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, header, err := ReadManifest(strings.NewReader(tt.src))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadManifest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadManifest() got = %+v, want %+v", got, tt.want)
+			}
+			if !tt.wantErr && header != tt.wantHeader {
+				t.Errorf("ReadManifest() header = %v, want %v", header, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestUnchanged(t *testing.T) {
+	mf := Manifest{Version: Version, Cities: 10, Names: "letters", Topology: "ring", Seed: 3}
+	m, err := Generate(mf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var src bytes.Buffer
+	src.WriteString(mf.Header() + "\n")
+	if err := encoding.MarshalTxt(&src, m); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unchanged(bytes.NewReader(src.Bytes()), m); err != nil {
+		t.Errorf("Unchanged() error = %v", err)
+	}
+	changed := m.Clone()
+	changed.DestroyCity(changed.ListCities()[0])
+	if err := Unchanged(bytes.NewReader(src.Bytes()), changed); err == nil {
+		t.Errorf("Unchanged() accepted the changed map")
+	}
+}
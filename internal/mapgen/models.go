@@ -0,0 +1,258 @@
+package mapgen
+
+import (
+	"errors"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"math"
+	"math/rand"
+)
+
+// ModelOptions holds parameters of the random graph models
+type ModelOptions struct {
+	P      float64 // probability of the link between every pair of Cities of the Erdős–Rényi model
+	M      int     // links of every new City of the Barabási–Albert model, extra links of every City of the strongly connected model
+	K      int     // number of nearest neighbours of every City of the Watts–Strogatz model, even
+	Beta   float64 // probability of the link rewiring of the Watts–Strogatz model
+	OneWay bool    // Cities are linked by a single road instead of two opposite roads
+	// Version of the generator, 0 means the current one
+	Version int
+}
+
+// Model links Cities according to the random graph model. Links are created with LinkCities in random
+// free directions: Cities are linked by two opposite roads, unless the links are one-way. Number of roads
+// of the City is limited by the number of active directions, links exceeding the limit are skipped.
+// Every City left without out-roads is repaired by the link to the random City, as the loader requires.
+// The legacy version 1 does not repair Cities, isolated ones are dropped.
+func Model(t Topology, names []string, opts ModelOptions, rnd *rand.Rand) (domain.Map, error) {
+	l := &linker{m: newMap(names), names: names, oneWay: opts.OneWay, rnd: rnd, legacy: opts.Version == legacyVersion}
+	if l.legacy {
+		l.m = domain.Map{}
+	}
+	n := len(names)
+	limit := domain.ActiveDirections().Len()
+	switch t {
+	case TopologyErdosRenyi:
+		if opts.P < 0 || opts.P > 1 {
+			return nil, fmt.Errorf("invalid link probability %v", opts.P)
+		}
+		erdosRenyi(l, n, opts.P)
+	case TopologyBarabasiAlbert:
+		if opts.M < 1 || opts.M > limit {
+			return nil, fmt.Errorf("links of the new city must be between 1 and %d", limit)
+		}
+		barabasiAlbert(l, n, opts.M)
+	case TopologyWattsStrogatz:
+		if opts.K < 2 || opts.K%2 != 0 || opts.K > limit || opts.K >= n {
+			return nil, fmt.Errorf("number of neighbours must be even, between 2 and %d and less than number of cities", limit)
+		}
+		if opts.Beta < 0 || opts.Beta > 1 {
+			return nil, fmt.Errorf("invalid rewiring probability %v", opts.Beta)
+		}
+		wattsStrogatz(l, n, opts.K, opts.Beta)
+	case TopologyStrong:
+		if opts.M < 0 {
+			return nil, fmt.Errorf("invalid number of extra links %d", opts.M)
+		}
+		if err := stronglyConnected(l, n, opts.M); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%v is not a random graph model", t)
+	}
+	if l.legacy {
+		return l.m, nil
+	}
+	if err := repair(l, n); err != nil {
+		return nil, err
+	}
+	return l.m, nil
+}
+
+// repair links every City without out-roads to the random City, the road leads from the repaired City.
+// Candidates are tried in order from the random one, so the City is linked whenever any link is possible.
+func repair(l *linker, n int) error {
+	for v, name := range l.names {
+		if len(l.m[name].OutRoad) != 0 {
+			continue
+		}
+		linked := false
+		for i, start := 0, l.rnd.Intn(n); !linked && i < n; i++ {
+			linked = l.link(v, (start+i)%n, true)
+		}
+		if !linked {
+			return fmt.Errorf("city %s can not be linked to any other city", name)
+		}
+	}
+	return nil
+}
+
+// erdosRenyi links every pair of Cities with probability p. Pairs without link are skipped
+// with the geometric distribution, so sparse maps of many Cities are generated fast.
+func erdosRenyi(l *linker, n int, p float64) {
+	if p == 0 {
+		return
+	}
+	// pairs (v, w) with w < v are enumerated row by row
+	v, w := 1, -1
+	for v < n {
+		skip := 0
+		if p < 1 {
+			skip = int(math.Log(1-l.rnd.Float64()) / math.Log(1-p))
+		}
+		for w += 1 + skip; w >= v && v < n; v++ {
+			w -= v
+		}
+		if v < n {
+			l.link(v, w, false)
+		}
+	}
+}
+
+// barabasiAlbert adds Cities one by one, every new City is linked to m distinct Cities chosen with probability
+// proportional to their number of links. The first Cities are linked to all previous Cities.
+func barabasiAlbert(l *linker, n, m int) {
+	// ends lists both ends of every link, so the random element is chosen proportionally to the links number
+	var ends []int
+	for v := 1; v < n; v++ {
+		if v <= m {
+			for w := 0; w < v; w++ {
+				if l.link(v, w, false) {
+					ends = append(ends, v, w)
+				}
+			}
+			continue
+		}
+		linked := 0
+		for attempt := 0; linked < m && attempt < 20*m; attempt++ {
+			// Cities with all directions taken can not be linked, so every other attempt
+			// chooses uniformly to reach Cities with free directions
+			w := l.rnd.Intn(v)
+			if len(ends) != 0 && attempt%2 == 0 {
+				w = ends[l.rnd.Intn(len(ends))]
+			}
+			if l.link(v, w, false) {
+				ends = append(ends, v, w)
+				linked++
+			}
+		}
+		// the new City stays connected while any previous City has a free direction
+		for i, start := 0, l.rnd.Intn(v); linked == 0 && i < v; i++ {
+			if w := (start + i) % v; l.link(v, w, false) {
+				ends = append(ends, v, w)
+				linked++
+			}
+		}
+	}
+}
+
+// wattsStrogatz lays Cities on the ring and links every City to k/2 next Cities, then every link
+// is rewired to the random City with probability beta.
+func wattsStrogatz(l *linker, n, k int, beta float64) {
+	for v := 0; v < n; v++ {
+		for j := 1; j <= k/2; j++ {
+			w := (v + j) % n
+			if l.rnd.Float64() < beta {
+				w = l.rnd.Intn(n)
+			}
+			l.link(v, w, false)
+		}
+	}
+}
+
+// stronglyConnected links all Cities in random order into the cycle, so every City is reachable from every
+// other one, then adds m random links of every City.
+func stronglyConnected(l *linker, n, m int) error {
+	if n < 3 {
+		return errors.New("strongly connected model requires at least 3 cities")
+	}
+	order := l.rnd.Perm(n)
+	for i, v := range order {
+		if !l.link(v, order[(i+1)%n], true) {
+			return errors.New("not enough directions to link cities into the cycle")
+		}
+	}
+	for v := 0; v < n; v++ {
+		for j := 0; j < m; j++ {
+			l.link(v, l.rnd.Intn(n), false)
+		}
+	}
+	return nil
+}
+
+// linker links Cities of the Map by their indices in random free directions
+type linker struct {
+	m      domain.Map
+	names  []string
+	oneWay bool
+	rnd    *rand.Rand
+	legacy bool // links of the generator version 1: Cities are created by links, rejected links are not retried
+}
+
+// link links Cities v and w. One-way road leads from v to w if the link is oriented, otherwise in random
+// orientation. Directions rejected by LinkCities are rolled back and the next free one is tried.
+// Returns false if the Cities are the same or already linked, or there is no free direction.
+func (l *linker) link(v, w int, oriented bool) bool {
+	if v == w || l.linked(v, w) {
+		return false
+	}
+	if l.oneWay && !oriented && l.rnd.Intn(2) == 0 {
+		v, w = w, v
+	}
+	from, to := l.names[v], l.names[w]
+	dirs := domain.ActiveDirections().All()
+	l.rnd.Shuffle(len(dirs), func(i, j int) {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	})
+	for _, d := range dirs {
+		if l.taken(from, d) || !l.oneWay && l.taken(to, d.Opposite()) {
+			continue
+		}
+		if err := l.m.LinkCities(from, to, d); err != nil {
+			if l.legacy {
+				return false
+			}
+			continue
+		}
+		if !l.oneWay {
+			if err := l.m.LinkCities(to, from, d.Opposite()); err != nil {
+				if l.legacy {
+					return false
+				}
+				l.m.DestroyRoad(l.m[from], d)
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// taken reports whether the out-road of the City in the Direction exists
+func (l *linker) taken(name string, d domain.Direction) bool {
+	city, ok := l.m[name]
+	if !ok {
+		return false
+	}
+	_, ok = city.OutRoad[d]
+	return ok
+}
+
+// linked reports whether there is a road between Cities v and w in any direction
+func (l *linker) linked(v, w int) bool {
+	a, b := l.m[l.names[v]], l.m[l.names[w]]
+	if a == nil || b == nil {
+		return false
+	}
+	for _, next := range a.OutRoad {
+		if next == b {
+			return true
+		}
+	}
+	for _, next := range b.OutRoad {
+		if next == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package mapgen
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/graph"
+	"math/rand"
+	"testing"
+)
+
+func TestModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		t       Topology
+		n       int
+		opts    ModelOptions
+		check   func(t *testing.T, m domain.Map)
+		wantErr bool
+	}{
+		{
+			name: "Erdos-Renyi",
+			t:    TopologyErdosRenyi,
+			n:    2000,
+			opts: ModelOptions{P: 0.0005},
+			check: func(t *testing.T, m domain.Map) {
+				// expected number of links is 2000*1999/2*0.0005 ≈ 1000, about 2000/e Cities are left isolated
+				// and repaired, some of them are linked to each other
+				if links := roads(m) / 2; links < 1450 || links > 1750 {
+					t.Errorf("Model() created %d links, want about 1600", links)
+				}
+			},
+		},
+		{
+			name: "Erdos-Renyi without links",
+			t:    TopologyErdosRenyi,
+			n:    100,
+			check: func(t *testing.T, m domain.Map) {
+				if len(m) != 100 {
+					t.Errorf("Model() created %d cities, want 100", len(m))
+				}
+				for _, city := range m {
+					if len(city.OutRoad) == 0 {
+						t.Errorf("isolated city %s is not repaired", city)
+					}
+				}
+			},
+		},
+		{name: "Single city", t: TopologyErdosRenyi, n: 1, opts: ModelOptions{P: 1}, wantErr: true},
+		{name: "Invalid probability", t: TopologyErdosRenyi, n: 10, opts: ModelOptions{P: 2}, wantErr: true},
+		{
+			name: "Barabasi-Albert",
+			t:    TopologyBarabasiAlbert,
+			n:    1000,
+			opts: ModelOptions{M: 2},
+			check: func(t *testing.T, m domain.Map) {
+				if len(m) != 1000 || len(graph.New(m).WeaklyConnected()) != 1 {
+					t.Errorf("Model() cities are not connected")
+				}
+				hubs := 0
+				for _, city := range m {
+					if len(city.OutRoad) == 4 {
+						hubs++
+					}
+				}
+				if hubs == 0 {
+					t.Errorf("Model() created no hubs")
+				}
+			},
+		},
+		{name: "Too many links", t: TopologyBarabasiAlbert, n: 10, opts: ModelOptions{M: 5}, wantErr: true},
+		{
+			name: "Watts-Strogatz ring",
+			t:    TopologyWattsStrogatz,
+			n:    10,
+			opts: ModelOptions{K: 2},
+			check: func(t *testing.T, m domain.Map) {
+				for _, city := range m {
+					if len(city.OutRoad) != 2 {
+						t.Errorf("city %s has %d roads, want 2", city, len(city.OutRoad))
+					}
+				}
+				if len(graph.New(m).StronglyConnected()) != 1 {
+					t.Errorf("Model() ring is not strongly connected")
+				}
+			},
+		},
+		{
+			name: "Watts-Strogatz small world",
+			t:    TopologyWattsStrogatz,
+			n:    1000,
+			opts: ModelOptions{K: 4, Beta: 0.1},
+			check: func(t *testing.T, m domain.Map) {
+				if links := roads(m) / 2; links < 1800 || links > 2000 {
+					t.Errorf("Model() created %d links, want about 2000", links)
+				}
+			},
+		},
+		{name: "Odd neighbours", t: TopologyWattsStrogatz, n: 10, opts: ModelOptions{K: 3}, wantErr: true},
+		{
+			name: "Strongly connected",
+			t:    TopologyStrong,
+			n:    1000,
+			opts: ModelOptions{M: 1},
+			check: func(t *testing.T, m domain.Map) {
+				if c := graph.New(m).StronglyConnected(); len(c) != 1 || len(c[0]) != 1000 {
+					t.Errorf("Model() has %d strongly connected components", len(c))
+				}
+			},
+		},
+		{
+			name: "Strongly connected one-way",
+			t:    TopologyStrong,
+			n:    1000,
+			opts: ModelOptions{OneWay: true},
+			check: func(t *testing.T, m domain.Map) {
+				if c := graph.New(m).StronglyConnected(); len(c) != 1 || roads(m) != 1000 {
+					t.Errorf("Model() has %d strongly connected components and %d roads", len(c), roads(m))
+				}
+			},
+		},
+		{name: "Geometric topology", t: TopologyGrid, n: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Model(tt.t, sequentialNames("C", tt.n, false), tt.opts, rand.New(rand.NewSource(1)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Model() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			for _, city := range m {
+				for d, next := range city.OutRoad {
+					if next == city {
+						t.Fatalf("road %s %v leads to itself", city, d)
+					}
+				}
+			}
+			if !tt.opts.OneWay && len(m.CheckConsistency()) != 0 {
+				t.Errorf("Model() roads are inconsistent")
+			}
+			tt.check(t, m)
+		})
+	}
+}
+
+// roads counts roads of the Map
+func roads(m domain.Map) int {
+	n := 0
+	for _, city := range m {
+		n += len(city.OutRoad)
+	}
+	return n
+}
@@ -0,0 +1,132 @@
+package mapgen
+
+import (
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"github.com/zippunov/alien-invasion/internal/names"
+	"math/rand"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// letters names Cities of the small maps
+const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// placeSyllables build the generated City names
+var placeSyllables = names.Syllables{
+	Onsets:  []string{"b", "c", "d", "f", "g", "h", "k", "l", "m", "n", "p", "r", "s", "t", "v", "w", "br", "dr", "gr", "kr", "st", "th", "tr"},
+	Vowels:  []string{"a", "e", "i", "o", "u", "ae", "ai", "ea", "ia", "ou"},
+	Endings: []string{"", "", "n", "r", "l", "s", "th", "nd", "rk", "ll", "ston", "burg", "dor", "mar", "heim", "wick"},
+}
+
+// Names creates n unique City names according to the names spec:
+//
+//	letters              single capital letters in random order, up to 26 Cities
+//	sequential[:PREFIX]  prefix followed by the City number, e.g. City0001. Default prefix: City
+//	syllable             random pronounceable fantasy names
+//	<PATH>               names drawn at random from the word list file, one word per line
+//
+// With noDigits names must not contain digits: sequential names are numbered with letters, e.g. CityA, CityAA,
+// and the word list must not contain words with digits.
+func Names(spec string, n int, noDigits bool, rnd *rand.Rand) ([]string, error) {
+	switch {
+	case spec == "letters":
+		return letterNames(n, rnd)
+	case spec == "sequential" || strings.HasPrefix(spec, "sequential:"):
+		prefix := "City"
+		if strings.HasPrefix(spec, "sequential:") {
+			prefix = strings.TrimPrefix(spec, "sequential:")
+		}
+		if noDigits && hasDigit(prefix) {
+			return nil, fmt.Errorf("prefix %s contains digits", prefix)
+		}
+		return sequentialNames(prefix, n, noDigits), nil
+	case spec == "syllable":
+		return placeSyllables.GenerateNames(n, rnd), nil
+	}
+	return wordListNames(spec, n, noDigits, rnd)
+}
+
+// letterNames names Cities with distinct capital letters in random order
+func letterNames(n int, rnd *rand.Rand) ([]string, error) {
+	if n > len(letters) {
+		return nil, fmt.Errorf("max number of cities is %d for letters names", len(letters))
+	}
+	b := []byte(letters)
+	rnd.Shuffle(len(b), func(i, j int) {
+		b[i], b[j] = b[j], b[i]
+	})
+	names := make([]string, n)
+	for i := range names {
+		names[i] = string(b[i])
+	}
+	return names, nil
+}
+
+// sequentialNames names Cities with the prefix followed by the City number counting from 1.
+// Numbers are padded with zeros to the same width, at least 4 digits. Without digits numbers are written
+// with letters: A, B, ..., Z, AA, AB and so on.
+func sequentialNames(prefix string, n int, noDigits bool) []string {
+	names := make([]string, n)
+	width := len(fmt.Sprint(n))
+	if width < 4 {
+		width = 4
+	}
+	for i := range names {
+		if noDigits {
+			names[i] = prefix + letterNumber(i+1)
+		} else {
+			names[i] = fmt.Sprintf("%s%0*d", prefix, width, i+1)
+		}
+	}
+	return names
+}
+
+// letterNumber writes positive number in the bijective base-26 numeration with letters A to Z
+func letterNumber(k int) string {
+	var b []byte
+	for ; k > 0; k = (k - 1) / 26 {
+		b = append(b, letters[(k-1)%26])
+	}
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// wordListNames draws n distinct names at random from the word list file
+func wordListNames(path string, n int, noDigits bool, rnd *rand.Rand) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	words, err := encoding.UnmarshalNames(f)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(words))
+	unique := words[:0]
+	for _, word := range words {
+		if noDigits && hasDigit(word) {
+			return nil, fmt.Errorf("word %s of the word list %s contains digits", word, path)
+		}
+		if !seen[word] {
+			seen[word] = true
+			unique = append(unique, word)
+		}
+	}
+	if len(unique) < n {
+		return nil, fmt.Errorf("word list %s has %d distinct words, but number of cities is %d", path, len(unique), n)
+	}
+	rnd.Shuffle(len(unique), func(i, j int) {
+		unique[i], unique[j] = unique[j], unique[i]
+	})
+	return unique[:n], nil
+}
+
+// hasDigit reports whether the string contains any digit
+func hasDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
@@ -0,0 +1,90 @@
+package mapgen
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	words := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(words, []byte("# towns\nFoo\nBar\nBaz\nFoo\nQux\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	digits := filepath.Join(t.TempDir(), "digits.txt")
+	if err := os.WriteFile(digits, []byte("Foo\nR2D2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		spec     string
+		n        int
+		noDigits bool
+		want     []string // expected names in any order, nil if only uniqueness is checked
+		wantErr  bool
+	}{
+		{name: "Letters", spec: "letters", n: 26},
+		{name: "Too many letters", spec: "letters", n: 27, wantErr: true},
+		{name: "Sequential", spec: "sequential", n: 3, want: []string{"City0001", "City0002", "City0003"}},
+		{name: "Sequential wide", spec: "sequential:Town", n: 12345},
+		{name: "Sequential without digits", spec: "sequential", n: 28, noDigits: true},
+		{name: "Prefix with digits", spec: "sequential:Area51", n: 1, noDigits: true, wantErr: true},
+		{name: "Syllable", spec: "syllable", n: 20000, noDigits: true},
+		{name: "Word list", spec: words, n: 4, want: []string{"Bar", "Baz", "Foo", "Qux"}},
+		{name: "Short word list", spec: words, n: 5, wantErr: true},
+		{name: "Word list with digits", spec: digits, n: 1, noDigits: true, wantErr: true},
+		{name: "Missing word list", spec: filepath.Join(t.TempDir(), "missing.txt"), n: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Names(tt.spec, tt.n, tt.noDigits, rand.New(rand.NewSource(1)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Names() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != tt.n {
+				t.Fatalf("Names() returned %d names, want %d", len(got), tt.n)
+			}
+			seen := map[string]bool{}
+			for _, name := range got {
+				if seen[name] || len(name) == 0 || strings.ContainsAny(name, " =") {
+					t.Fatalf("Names() invalid or duplicate name %q", name)
+				}
+				if tt.noDigits && hasDigit(name) {
+					t.Fatalf("Names() name %q contains digits", name)
+				}
+				seen[name] = true
+			}
+			if tt.want != nil {
+				for _, name := range tt.want {
+					if !seen[name] {
+						t.Errorf("Names() = %v, want %v", got, tt.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_sequentialNames(t *testing.T) {
+	got := sequentialNames("C", 28, true)
+	if got[0] != "CA" || got[25] != "CZ" || got[26] != "CAA" || got[27] != "CAB" {
+		t.Errorf("sequentialNames() = %v", got)
+	}
+	if got := sequentialNames("City", 100000, false); got[0] != "City000001" {
+		t.Errorf("sequentialNames() first name = %s, want City000001", got[0])
+	}
+}
+
+func TestNames_seed(t *testing.T) {
+	a, _ := Names("syllable", 100, false, rand.New(rand.NewSource(7)))
+	b, _ := Names("syllable", 100, false, rand.New(rand.NewSource(7)))
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Names() differs for the same seed")
+	}
+}
@@ -0,0 +1,68 @@
+package mapgen
+
+import (
+	"errors"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"math/rand"
+)
+
+// Random links every City with 1 to all active directions random out-roads to distinct random other Cities.
+// Directions of the roads have no spatial meaning.
+func Random(names []string, rnd *rand.Rand) (domain.Map, error) {
+	if len(names) < 2 {
+		return nil, errors.New("random topology requires at least 2 cities")
+	}
+	m := newMap(names)
+	for i, name := range names {
+		dirs := randomDirections(rnd)
+		others := otherCitiesRandom(len(names), i, len(dirs), rnd)
+		for k, other := range others {
+			if err := m.LinkCities(name, names[other], dirs[k]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// newMap creates the Map of the given Cities without roads, so Cities are never lost by the generator
+func newMap(names []string) domain.Map {
+	m := make(domain.Map, len(names))
+	for _, name := range names {
+		m.InitCity(name)
+	}
+	return m
+}
+
+// otherCitiesRandom picks up to k distinct random indices of n Cities other than the City i
+func otherCitiesRandom(n, i, k int, rnd *rand.Rand) []int {
+	if k > n-1 {
+		k = n - 1
+	}
+	result := make([]int, 0, k)
+	for len(result) < k {
+		if other := rnd.Intn(n); other != i && !containsInt(result, other) {
+			result = append(result, other)
+		}
+	}
+	return result
+}
+
+// containsInt reports whether the short list contains given value
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// randomDirections returns random non-empty subset of the active directions in random order
+func randomDirections(rnd *rand.Rand) []domain.Direction {
+	dirs := domain.ActiveDirections().All()
+	rnd.Shuffle(len(dirs), func(i, j int) {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	})
+	return dirs[:rnd.Intn(len(dirs))+1]
+}
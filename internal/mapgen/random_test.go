@@ -0,0 +1,31 @@
+package mapgen
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"math/rand"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	names := sequentialNames("City", 1000, false)
+	m, err := Random(names, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("Random() error = %v", err)
+	}
+	if len(m) != len(names) {
+		t.Fatalf("Random() linked %d cities, want %d", len(m), len(names))
+	}
+	max := len(domain.ActiveDirections().All())
+	for _, city := range m {
+		if len(city.OutRoad) == 0 || len(city.OutRoad) > max {
+			t.Errorf("city %s has %d roads", city, len(city.OutRoad))
+		}
+		targets := map[*domain.City]bool{}
+		for d, next := range city.OutRoad {
+			if next == city || targets[next] {
+				t.Errorf("road %s %v=%s leads to itself or duplicates another road", city, d, next)
+			}
+			targets[next] = true
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package mapgen
+
+import (
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// Topology is an integer value that identifies the layout of the generated Cities.
+type Topology uint
+
+// Enumeration of all topologies
+const (
+	// TopologyRandom links Cities to random other Cities in random directions
+	TopologyRandom Topology = iota
+	// TopologyGrid lays Cities on the rectangular grid row by row, neighbours are linked north, south, east and west
+	TopologyGrid
+	// TopologyTorus is the grid with the opposite edges linked together
+	TopologyTorus
+	// TopologyHex lays Cities on the hexagonal grid with rows shifted by half of the cell, neighbours are linked
+	// east, west, northeast, northwest, southeast and southwest
+	TopologyHex
+	// TopologyRing lays Cities on the circle, neighbours are linked east and west
+	TopologyRing
+	// TopologyLine lays Cities on the line, neighbours are linked east and west
+	TopologyLine
+	// TopologyErdosRenyi links every pair of Cities with the same probability
+	TopologyErdosRenyi
+	// TopologyBarabasiAlbert adds Cities one by one linking them preferentially to Cities with many roads
+	TopologyBarabasiAlbert
+	// TopologyWattsStrogatz is the small world: ring of Cities linked to the nearest neighbours with random shortcuts
+	TopologyWattsStrogatz
+	// TopologyStrong links Cities into the random cycle with extra random links, every City is reachable from
+	// every other one
+	TopologyStrong
+)
+
+// topologyMap links string names to the Topology enumeration
+var topologyMap = map[string]Topology{
+	"random":             TopologyRandom,
+	"grid":               TopologyGrid,
+	"torus":              TopologyTorus,
+	"hex":                TopologyHex,
+	"ring":               TopologyRing,
+	"line":               TopologyLine,
+	"erdos-renyi":        TopologyErdosRenyi,
+	"barabasi-albert":    TopologyBarabasiAlbert,
+	"watts-strogatz":     TopologyWattsStrogatz,
+	"strongly-connected": TopologyStrong,
+}
+
+// topologyNames links Topology enumeration to their names
+var topologyNames = map[Topology]string{
+	TopologyRandom:         "random",
+	TopologyGrid:           "grid",
+	TopologyTorus:          "torus",
+	TopologyHex:            "hex",
+	TopologyRing:           "ring",
+	TopologyLine:           "line",
+	TopologyErdosRenyi:     "erdos-renyi",
+	TopologyBarabasiAlbert: "barabasi-albert",
+	TopologyWattsStrogatz:  "watts-strogatz",
+	TopologyStrong:         "strongly-connected",
+}
+
+// TopologyByName fetches Topology by its name.
+// Returns (0, false) if given name not found
+func TopologyByName(name string) (Topology, bool) {
+	result, ok := topologyMap[strings.ToLower(name)]
+	return result, ok
+}
+
+// String is a part of the Stringer interface implementation for the Topology
+func (t Topology) String() string {
+	return topologyNames[t]
+}
+
+// latticeDirections describes directions of the roads of the geometric topologies
+var latticeDirections = map[Topology]string{
+	TopologyGrid:  "north, south, east and west",
+	TopologyTorus: "north, south, east and west",
+	TopologyHex:   "east, west, northeast, northwest, southeast and southwest",
+	TopologyRing:  "east and west",
+	TopologyLine:  "east and west",
+}
+
+// LatticeOptions holds parameters of the geometric topologies
+type LatticeOptions struct {
+	Width  int     // number of columns of the grid, torus and hex, 0 means the square layout
+	OneWay bool    // neighbours are linked by a single road in the random direction instead of two opposite roads
+	Remove float64 // probability of the removal of the link between neighbours
+	// Version of the generator, 0 means the current one
+	Version int
+}
+
+// link is the pair of neighbour Cities, the City to is in the direction from the City from
+type link struct {
+	from, to  int
+	direction domain.Direction
+}
+
+// Lattice lays Cities on the coordinates of the geometric topology and links true neighbours,
+// so the road directions have spatial meaning. Cities are placed row by row in order of names.
+// Every pair of neighbours is linked with two opposite roads, unless the links are one-way.
+// Every City keeps at least one road to the neighbour, removed or one-way links are repaired when needed.
+// The legacy version 1 does not repair Cities, isolated ones are dropped.
+func Lattice(t Topology, names []string, opts LatticeOptions, rnd *rand.Rand) (domain.Map, error) {
+	links, err := latticeLinks(t, len(names), opts.Width)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range links {
+		if !isActive(l.direction) || !isActive(l.direction.Opposite()) {
+			return nil, fmt.Errorf("%v topology requires %s directions", t, latticeDirections[t])
+		}
+	}
+	if opts.Version == legacyVersion {
+		return legacyLattice(names, links, opts, rnd), nil
+	}
+	m := newMap(names)
+	for _, l := range links {
+		if opts.Remove > 0 && rnd.Float64() < opts.Remove {
+			continue
+		}
+		from, to := names[l.from], names[l.to]
+		var err error
+		switch {
+		case !opts.OneWay:
+			if err = m.LinkCities(from, to, l.direction); err == nil {
+				err = m.LinkCities(to, from, l.direction.Opposite())
+			}
+		case rnd.Intn(2) == 0:
+			err = m.LinkCities(from, to, l.direction)
+		default:
+			err = m.LinkCities(to, from, l.direction.Opposite())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%v topology of %d cities can not be laid: %v", t, len(names), err)
+		}
+	}
+	if err := repairLattice(m, names, links); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// legacyLattice links neighbours as the generator version 1 did: Cities are created by the links,
+// links rejected by LinkCities are skipped and Cities left without roads are dropped
+func legacyLattice(names []string, links []link, opts LatticeOptions, rnd *rand.Rand) domain.Map {
+	m := domain.Map{}
+	for _, l := range links {
+		if opts.Remove > 0 && rnd.Float64() < opts.Remove {
+			continue
+		}
+		from, to := names[l.from], names[l.to]
+		switch {
+		case !opts.OneWay:
+			_ = m.LinkCities(from, to, l.direction)
+			_ = m.LinkCities(to, from, l.direction.Opposite())
+		case rnd.Intn(2) == 0:
+			_ = m.LinkCities(from, to, l.direction)
+		default:
+			_ = m.LinkCities(to, from, l.direction.Opposite())
+		}
+	}
+	return m
+}
+
+// repairLattice restores the road to the neighbour of every City left without out-roads by the removal
+// of links or by one-way links, because the loader requires at least one out-road of every City.
+// The road back is restored as well, unless its direction is taken.
+func repairLattice(m domain.Map, names []string, links []link) error {
+	var neighbours map[int][]link // links of every City, indexed on the first repair
+	for i, name := range names {
+		city := m[name]
+		if len(city.OutRoad) != 0 {
+			continue
+		}
+		if neighbours == nil {
+			neighbours = make(map[int][]link, len(names))
+			for _, l := range links {
+				neighbours[l.from] = append(neighbours[l.from], l)
+				neighbours[l.to] = append(neighbours[l.to], link{l.to, l.from, l.direction.Opposite()})
+			}
+		}
+		ls := neighbours[i]
+		if len(ls) == 0 {
+			return fmt.Errorf("city %s has no neighbours to link", name)
+		}
+		l := ls[0]
+		if err := m.LinkCities(name, names[l.to], l.direction); err != nil {
+			return err
+		}
+		if _, ok := m[names[l.to]].OutRoad[l.direction.Opposite()]; !ok {
+			if err := m.LinkCities(names[l.to], name, l.direction.Opposite()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// latticeLinks lists pairs of neighbours of n Cities laid according to the topology
+func latticeLinks(t Topology, n, width int) ([]link, error) {
+	switch t {
+	case TopologyLine, TopologyRing:
+		if t == TopologyRing && n < 3 {
+			return nil, fmt.Errorf("ring requires at least 3 cities")
+		}
+		links := make([]link, 0, n)
+		for i := 0; i+1 < n; i++ {
+			links = append(links, link{i, i + 1, domain.East})
+		}
+		if t == TopologyRing {
+			links = append(links, link{n - 1, 0, domain.East})
+		}
+		return links, nil
+	case TopologyGrid, TopologyTorus, TopologyHex:
+		if width <= 0 {
+			width = int(math.Ceil(math.Sqrt(float64(n))))
+		}
+		rows := (n + width - 1) / width
+		if t == TopologyTorus && (n%width != 0 || width < 3 || rows < 3) {
+			return nil, fmt.Errorf("torus requires at least 3 full rows of at least 3 cities, got %d cities in rows of %d", n, width)
+		}
+		var links []link
+		at := func(r, c int) (int, bool) {
+			if t == TopologyTorus {
+				r, c = (r+rows)%rows, (c+width)%width
+			}
+			i := r*width + c
+			return i, r >= 0 && c >= 0 && c < width && i < n
+		}
+		add := func(i, r, c int, d domain.Direction) {
+			if j, ok := at(r, c); ok {
+				links = append(links, link{i, j, d})
+			}
+		}
+		for i := 0; i < n; i++ {
+			r, c := i/width, i%width
+			add(i, r, c+1, domain.East)
+			if t != TopologyHex {
+				add(i, r+1, c, domain.South)
+				continue
+			}
+			// odd rows are shifted east by half of the cell
+			shift := r % 2
+			add(i, r+1, c+shift, domain.SouthEast)
+			add(i, r+1, c+shift-1, domain.SouthWest)
+		}
+		return links, nil
+	}
+	return nil, fmt.Errorf("%v is not a geometric topology", t)
+}
+
+// isActive reports whether the Direction belongs to the active direction set
+func isActive(d domain.Direction) bool {
+	for _, active := range domain.ActiveDirections().All() {
+		if active == d {
+			return true
+		}
+	}
+	return false
+}
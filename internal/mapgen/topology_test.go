@@ -0,0 +1,113 @@
+package mapgen
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/domain/domaintest"
+	"math/rand"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	domaintest.Main(m)
+}
+
+func TestLattice(t *testing.T) {
+	names := func(n int) []string {
+		return sequentialNames("C", n, false)
+	}
+	tests := []struct {
+		name      string
+		topology  Topology
+		n         int
+		opts      LatticeOptions
+		set       string
+		wantRoads int
+		wantErr   bool
+	}{
+		{name: "Grid", topology: TopologyGrid, n: 9, wantRoads: 24},
+		{name: "Partial grid", topology: TopologyGrid, n: 8, opts: LatticeOptions{Width: 3}, wantRoads: 20},
+		{name: "Torus", topology: TopologyTorus, n: 12, opts: LatticeOptions{Width: 4}, wantRoads: 48},
+		{name: "Narrow torus", topology: TopologyTorus, n: 8, opts: LatticeOptions{Width: 4}, wantErr: true},
+		{name: "Hex", topology: TopologyHex, n: 9, set: "compass8", wantRoads: 32},
+		{name: "Hex without diagonals", topology: TopologyHex, n: 9, wantErr: true},
+		{name: "Ring", topology: TopologyRing, n: 5, wantRoads: 10},
+		{name: "Small ring", topology: TopologyRing, n: 2, wantErr: true},
+		{name: "Line", topology: TopologyLine, n: 5, wantRoads: 8},
+		// 12 one-way links, Cities left with in-roads only are repaired with the road to the neighbour
+		{name: "One-way grid", topology: TopologyGrid, n: 9, opts: LatticeOptions{OneWay: true}, wantRoads: 14},
+		// every City is repaired with the link to its first neighbour
+		{name: "Removed roads", topology: TopologyGrid, n: 9, opts: LatticeOptions{Remove: 1}, wantRoads: 16},
+		{name: "Single city line", topology: TopologyLine, n: 1, wantErr: true},
+		{name: "Random", topology: TopologyRandom, n: 9, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := tt.set
+			if len(set) == 0 {
+				set = "compass4"
+			}
+			if !domaintest.RunWithDirections(t, set) {
+				return
+			}
+			m, err := Lattice(tt.topology, names(tt.n), tt.opts, rand.New(rand.NewSource(1)))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Lattice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			roads := 0
+			for _, city := range m {
+				if len(city.OutRoad) == 0 {
+					t.Errorf("city %s has no out-roads", city)
+				}
+				roads += len(city.OutRoad)
+			}
+			if roads != tt.wantRoads {
+				t.Errorf("Lattice() created %d roads, want %d", roads, tt.wantRoads)
+			}
+			if !tt.opts.OneWay && len(m.CheckConsistency()) != 0 {
+				t.Errorf("Lattice() roads are inconsistent: %v", m.CheckConsistency())
+			}
+		})
+	}
+}
+
+func TestLattice_neighbours(t *testing.T) {
+	if !domaintest.RunWithDirections(t, "compass8") {
+		return
+	}
+	// hex rows of 3: C0001 C0002 C0003 / C0004 C0005 C0006 shifted east / C0007 C0008 C0009
+	m, err := Lattice(TopologyHex, sequentialNames("C", 9, false), LatticeOptions{}, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("Lattice() error = %v", err)
+	}
+	want := map[domain.Direction]string{
+		domain.East:      "C0006",
+		domain.West:      "C0004",
+		domain.NorthEast: "C0003",
+		domain.NorthWest: "C0002",
+		domain.SouthEast: "C0009",
+		domain.SouthWest: "C0008",
+	}
+	centre := m["C0005"]
+	if len(centre.OutRoad) != len(want) {
+		t.Fatalf("hex centre has roads %v", centre.OutRoad)
+	}
+	for d, name := range want {
+		if got := centre.OutRoad[d]; got == nil || got.Name != name {
+			t.Errorf("hex centre road %v leads to %v, want %s", d, got, name)
+		}
+	}
+	if got := m["C0001"].OutRoad[domain.SouthEast]; got == nil || got.Name != "C0004" {
+		t.Errorf("even row road southeast leads to %v, want C0004", got)
+	}
+}
+
+func TestTopologyByName(t *testing.T) {
+	for name, want := range topologyMap {
+		if got, ok := TopologyByName(name); !ok || got != want || got.String() != name {
+			t.Errorf("TopologyByName(%s) = %v, %v", name, got, ok)
+		}
+	}
+	if _, ok := TopologyByName("sphere"); ok {
+		t.Errorf("TopologyByName(sphere) must fail")
+	}
+}
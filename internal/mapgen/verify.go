@@ -0,0 +1,33 @@
+package mapgen
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"strings"
+)
+
+// Verify round-trips the Map through the Map text format and checks it against every rule of the loader:
+// the text must have no problems, and the loaded Map must be equal to the generated one.
+func Verify(m domain.Map) error {
+	var buf bytes.Buffer
+	if err := encoding.MarshalTxt(&buf, m); err != nil {
+		return err
+	}
+	problems, err := encoding.ValidateTxt(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return err
+	}
+	if len(problems) != 0 {
+		return problems
+	}
+	loaded := domain.Map{}
+	if err := encoding.UnmarshalTxt(&buf, loaded); err != nil {
+		return err
+	}
+	if diff := m.Diff(loaded); len(diff) != 0 {
+		return fmt.Errorf("loaded map differs from the generated one: %s", strings.Join(diff, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package mapgen
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	for _, topology := range []string{"random", "torus", "ring", "erdos-renyi", "barabasi-albert", "watts-strogatz", "strongly-connected"} {
+		for _, oneWay := range []bool{false, true} {
+			mf := Manifest{Version: Version, Cities: 100, Names: "syllable", Topology: topology, OneWay: oneWay,
+				Remove: 0.1, P: 0.01, M: 2, K: 2, Beta: 0.2, Seed: 1}
+			if topology == "torus" {
+				mf.Width = 10
+			}
+			m, err := Generate(mf)
+			if err != nil {
+				t.Fatalf("Generate(%s) error = %v", topology, err)
+			}
+			if len(m) != mf.Cities {
+				t.Errorf("Generate(%s) created %d cities, want %d", topology, len(m), mf.Cities)
+			}
+			if err := Verify(m); err != nil {
+				t.Errorf("Verify(%s) error = %v", topology, err)
+			}
+		}
+	}
+}
+
+func TestVerify_invalid(t *testing.T) {
+	// B has the in-road only, so it can not be defined on its own line
+	m := domain.Map{}
+	_ = m.LinkCities("A", "B", domain.North)
+	if err := Verify(m); err == nil {
+		t.Errorf("Verify() accepted the map which can not be loaded")
+	}
+}
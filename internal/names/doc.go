@@ -0,0 +1,5 @@
+/*
+Package names generates random pronounceable names, shared by the City names of the generated maps
+and the alien names.
+*/
+package names
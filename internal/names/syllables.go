@@ -0,0 +1,48 @@
+package names
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// Syllables of the generated pronounceable names. Every name is the run of onset and vowel pairs
+// closed by one of the endings, empty endings leave the name open.
+type Syllables struct {
+	Onsets  []string
+	Vowels  []string
+	Endings []string
+}
+
+// GenerateNames creates n unique capitalized names of 2 or 3 syllables. When generated names keep
+// colliding with the previous ones, names get one more syllable, so the generation always ends.
+// The same random source state gives the same names.
+func (s Syllables) GenerateNames(n int, rnd *rand.Rand) []string {
+	names := make([]string, n)
+	used := make(map[string]bool, n)
+	extra, collisions := 0, 0
+	for i := 0; i < n; {
+		name := s.name(rnd, 2+extra+rnd.Intn(2))
+		if used[name] {
+			if collisions++; collisions == 10 {
+				extra, collisions = extra+1, 0
+			}
+			continue
+		}
+		used[name] = true
+		names[i] = name
+		i, collisions = i+1, 0
+	}
+	return names
+}
+
+// name creates single capitalized name from the given number of random syllables
+func (s Syllables) name(rnd *rand.Rand, syllables int) string {
+	var b strings.Builder
+	for i := 0; i < syllables; i++ {
+		b.WriteString(s.Onsets[rnd.Intn(len(s.Onsets))])
+		b.WriteString(s.Vowels[rnd.Intn(len(s.Vowels))])
+	}
+	b.WriteString(s.Endings[rnd.Intn(len(s.Endings))])
+	name := b.String()
+	return strings.ToUpper(name[:1]) + name[1:]
+}
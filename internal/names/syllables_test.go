@@ -0,0 +1,24 @@
+package names
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSyllables_GenerateNames(t *testing.T) {
+	// the tiny syllables give 8 names of 2 syllables only, the rest must get more syllables
+	s := Syllables{Onsets: []string{"b", "k"}, Vowels: []string{"a"}, Endings: []string{"", "n"}}
+	got := s.GenerateNames(100, rand.New(rand.NewSource(1)))
+	seen := map[string]bool{}
+	for _, name := range got {
+		if seen[name] || strings.ContainsAny(name, " 0123456789") || strings.ToUpper(name[:1]) != name[:1] {
+			t.Fatalf("GenerateNames() invalid or duplicate name %q", name)
+		}
+		seen[name] = true
+	}
+	if again := s.GenerateNames(100, rand.New(rand.NewSource(1))); !reflect.DeepEqual(got, again) {
+		t.Errorf("GenerateNames() differs for the same seed")
+	}
+}
@@ -0,0 +1,98 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"github.com/zippunov/alien-invasion/internal/graph"
+	"io"
+)
+
+// IAnalysisInfra interface specifies functionality required from the application environment
+// by the Analysis usecase.
+type IAnalysisInfra interface {
+	In() io.Reader
+	InFormat() encoding.Format
+	Symmetric() bool
+	From() string
+	To() string
+}
+
+// Analysis is the usecase where the Map graph is analysed before any Scenario run:
+// connectivity, dead ends and traps, cycles and centrality of the Cities.
+type Analysis struct {
+	infra IAnalysisInfra
+}
+
+// AnalysisReport is the result of the Map graph analysis
+type AnalysisReport struct {
+	Cities            int
+	Roads             int
+	OneWayRoads       int                    // roads without the reverse road
+	ConflictingRoads  int                    // roads whose reverse direction in the destination City leads to another City
+	Inconsistencies   []domain.Inconsistency // every road breaking the Map symmetry, see domain.Map.CheckConsistency
+	Diameter          int                    // the longest of the shortest paths between Cities
+	StronglyConnected [][]string             // groups of Cities reachable from each other, the largest first
+	WeaklyConnected   [][]string             // groups of Cities connected regardless of the road directions, the largest first
+	Sinks             []string               // Cities without out-roads
+	Traps             [][]string             // groups of Cities Aliens can enter, but never leave
+	Cycle             []string               // example of the cycle of roads, nil if there are no cycles
+	Centrality        []graph.Centrality
+	From              string   // optional City the reachability is reported for
+	Reachable         []string // Cities reachable from the From City
+	To                string   // optional destination of the shortest path from the From City
+	Path              []string // the shortest path from the From City to the To City, nil if it is unreachable
+}
+
+// InitAnalysis usecase initialization with provided infrastructure
+func InitAnalysis(infra IAnalysisInfra) Analysis {
+	return Analysis{infra: infra}
+}
+
+// Run executes the Usecase and returns the report of the Map graph
+func (a *Analysis) Run() (AnalysisReport, error) {
+	m, _, err := loadMap(a.infra)
+	if err != nil {
+		return AnalysisReport{}, err
+	}
+	g := graph.New(m)
+	report := AnalysisReport{
+		Cities:            g.Len(),
+		Roads:             g.Roads(),
+		Inconsistencies:   m.CheckConsistency(),
+		StronglyConnected: g.StronglyConnected(),
+		WeaklyConnected:   g.WeaklyConnected(),
+		Sinks:             g.Sinks(),
+		Traps:             g.Traps(),
+		Cycle:             g.FindCycle(),
+		Centrality:        g.Centrality(),
+		From:              a.infra.From(),
+		To:                a.infra.To(),
+	}
+	for _, i := range report.Inconsistencies {
+		if i.Kind == domain.ConflictingRoad {
+			report.ConflictingRoads++
+		} else {
+			report.OneWayRoads++
+		}
+	}
+	for _, c := range report.Centrality {
+		if c.Eccentricity > report.Diameter {
+			report.Diameter = c.Eccentricity
+		}
+	}
+	if len(report.To) != 0 && len(report.From) == 0 {
+		return AnalysisReport{}, fmt.Errorf("path destination %s requires the origin city", report.To)
+	}
+	if len(report.From) != 0 {
+		if report.Reachable, err = g.Reachable(report.From); err != nil {
+			return AnalysisReport{}, err
+		}
+	}
+	if len(report.To) != 0 {
+		if report.Path, err = g.ShortestPath(report.From, report.To); err != nil {
+			return AnalysisReport{}, err
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testAnalysisInfra is an in-memory IAnalysisInfra implementation
+type testAnalysisInfra struct {
+	in        string
+	symmetric bool
+	from      string
+	to        string
+}
+
+func (i *testAnalysisInfra) In() io.Reader {
+	return strings.NewReader(i.in)
+}
+
+func (i *testAnalysisInfra) InFormat() encoding.Format {
+	return encoding.Txt
+}
+
+func (i *testAnalysisInfra) Symmetric() bool {
+	return i.symmetric
+}
+
+func (i *testAnalysisInfra) From() string {
+	return i.from
+}
+
+func (i *testAnalysisInfra) To() string {
+	return i.to
+}
+
+func TestAnalysis_Run(t *testing.T) {
+	src := `A north=B
+B south=A east=C
+C west=B north=D
+E south=D
+`
+	tests := []struct {
+		name    string
+		infra   *testAnalysisInfra
+		check   func(t *testing.T, report AnalysisReport)
+		wantErr bool
+	}{
+		{
+			name:  "Traps",
+			infra: &testAnalysisInfra{in: src, from: "E", to: "A"},
+			check: func(t *testing.T, report AnalysisReport) {
+				if report.Cities != 5 || report.Roads != 6 || report.OneWayRoads != 2 || report.ConflictingRoads != 0 || report.Diameter != 3 {
+					t.Errorf("Run() = %+v", report)
+				}
+				if !reflect.DeepEqual(report.Sinks, []string{"D"}) || !reflect.DeepEqual(report.Traps, [][]string{{"D"}}) {
+					t.Errorf("Run() sinks %v, traps %v", report.Sinks, report.Traps)
+				}
+				if !reflect.DeepEqual(report.Reachable, []string{"D"}) || report.Path != nil {
+					t.Errorf("Run() reachable %v, path %v", report.Reachable, report.Path)
+				}
+				if !reflect.DeepEqual(report.Cycle, []string{"A", "B", "A"}) {
+					t.Errorf("Run() cycle %v", report.Cycle)
+				}
+			},
+		},
+		{
+			name:  "Symmetric",
+			infra: &testAnalysisInfra{in: src, symmetric: true, from: "A", to: "E"},
+			check: func(t *testing.T, report AnalysisReport) {
+				if report.Inconsistencies != nil || len(report.StronglyConnected) != 1 || report.Traps != nil {
+					t.Errorf("Run() = %+v", report)
+				}
+				if !reflect.DeepEqual(report.Path, []string{"A", "B", "C", "D", "E"}) {
+					t.Errorf("Run() path %v", report.Path)
+				}
+			},
+		},
+		{
+			name:  "Conflicting road",
+			infra: &testAnalysisInfra{in: "A north=B\nB south=C\nC north=B\n"},
+			check: func(t *testing.T, report AnalysisReport) {
+				if report.OneWayRoads != 0 || report.ConflictingRoads != 1 || len(report.Inconsistencies) != 1 {
+					t.Fatalf("Run() = %+v", report)
+				}
+				if got, want := report.Inconsistencies[0].String(), "road A north=B conflicts with road B south=C"; got != want {
+					t.Errorf("Run() inconsistency %s, want %s", got, want)
+				}
+			},
+		},
+		{
+			name:    "Unknown city",
+			infra:   &testAnalysisInfra{in: src, from: "X"},
+			wantErr: true,
+		},
+		{
+			name:    "Destination without origin",
+			infra:   &testAnalysisInfra{in: src, to: "A"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := InitAnalysis(tt.infra)
+			report, err := a.Run()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, report)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package usecases
+
+import (
+	"errors"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"io"
+	"math/rand"
+	"sync"
+)
+
+// IBatchInfra interface specifies functionality required by the Batch usecase.
+// Settings of IInfra are shared by every Scenario run of the Batch.
+type IBatchInfra interface {
+	IInfra
+	Runs() int
+	Workers() int
+}
+
+// Batch is the usecase where many independent Scenario runs are executed in parallel
+// and their outcomes are aggregated into the BatchReport.
+type Batch struct {
+	infra    IBatchInfra
+	worldMap domain.Map // Map parsed once, every run works with its own clone
+	seeds    []int64    // seed of every run derived from the batch random source
+	workers  int
+}
+
+// BatchReport aggregates outcomes of the Batch runs
+type BatchReport struct {
+	Runs int
+	// DestructionProbability is the share of runs where the City was destroyed, for every City of the Map
+	DestructionProbability map[string]float64
+	// Survivors maps number of surviving Cities to the number of runs ended with that number of Cities
+	Survivors map[int]int
+	// Fights is the number of runs with at least one City or road destroyed
+	Fights int
+	// MeanLastDestructionTick is the mean tick of the last destruction over the runs with fights
+	MeanLastDestructionTick float64
+}
+
+// outcome is the result of the single Batch run required for the BatchReport
+type outcome struct {
+	destroyed           []string
+	survivors           int
+	lastDestructionTick int
+	fights              bool
+}
+
+// InitBatch batch initialization with provided infrastructure. Map is read once,
+// seeds of the runs are derived from the infrastructure random source.
+func InitBatch(infra IBatchInfra) (Batch, error) {
+	if infra.Runs() <= 0 {
+		return Batch{}, errors.New("number of runs must be greater than 0")
+	}
+	m, _, err := loadMap(infra)
+	if err != nil {
+		return Batch{}, err
+	}
+	rnd := infra.Rand()
+	seeds := make([]int64, infra.Runs())
+	for i := range seeds {
+		seeds[i] = rnd.Int63()
+	}
+	workers := infra.Workers()
+	if workers <= 0 {
+		workers = 1
+	}
+	b := Batch{infra: infra, worldMap: m, seeds: seeds, workers: workers}
+	// the first run reports invalid settings before any worker starts
+	if _, err := b.newScenario(0); err != nil {
+		return Batch{}, err
+	}
+	return b, nil
+}
+
+// Run executes every Scenario run of the Batch and aggregates their outcomes.
+// The report depends on the seeds only, not on the number of workers.
+func (b *Batch) Run() (BatchReport, error) {
+	outcomes := make([]outcome, len(b.seeds))
+	errs := make([]error, len(b.seeds))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < b.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				outcomes[i], errs[i] = b.runOnce(i)
+			}
+		}()
+	}
+	for i := range b.seeds {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return BatchReport{}, err
+		}
+	}
+	return b.aggregate(outcomes), nil
+}
+
+// runOnce executes the single Scenario run
+func (b *Batch) runOnce(i int) (outcome, error) {
+	s, err := b.newScenario(i)
+	if err != nil {
+		return outcome{}, err
+	}
+	if err := s.Run(); err != nil {
+		return outcome{}, err
+	}
+	stats := s.Stats()
+	return outcome{
+		destroyed:           stats.Destroyed,
+		survivors:           stats.CitiesSurvived,
+		lastDestructionTick: stats.LastDestructionTick,
+		fights:              stats.CitiesDestroyed+stats.RoadsDestroyed > 0,
+	}, nil
+}
+
+// aggregate builds BatchReport from the outcomes of every run
+func (b *Batch) aggregate(outcomes []outcome) BatchReport {
+	report := BatchReport{
+		Runs:                   len(outcomes),
+		DestructionProbability: make(map[string]float64, len(b.worldMap)),
+		Survivors:              map[int]int{},
+	}
+	destroyed := map[string]int{}
+	ticks := 0
+	for _, o := range outcomes {
+		for _, city := range o.destroyed {
+			destroyed[city]++
+		}
+		report.Survivors[o.survivors]++
+		if o.fights {
+			report.Fights++
+			ticks += o.lastDestructionTick
+		}
+	}
+	for city := range b.worldMap {
+		report.DestructionProbability[city] = float64(destroyed[city]) / float64(report.Runs)
+	}
+	if report.Fights > 0 {
+		report.MeanLastDestructionTick = float64(ticks) / float64(report.Fights)
+	}
+	return report
+}
+
+// newScenario creates Scenario of the i-th run on the clone of the Map. Resulting Map is discarded,
+// so the layout of the Map source is not needed.
+func (b *Batch) newScenario(i int) (Scenario, error) {
+	infra := &runInfra{IInfra: b.infra, rnd: rand.New(rand.NewSource(b.seeds[i]))}
+	return newScenario(infra, b.worldMap.Clone(), nil)
+}
+
+// runInfra is IInfra of the single Batch run. It shares settings of the Batch,
+// but owns the random source and discards every output.
+type runInfra struct {
+	IInfra
+	rnd *rand.Rand
+}
+
+// Out is a part of IInfra interface implementation
+func (r *runInfra) Out() io.Writer {
+	return io.Discard
+}
+
+// Dot is a part of IInfra interface implementation
+func (r *runInfra) Dot() io.Writer {
+	return nil
+}
+
+// Observers is a part of IInfra interface implementation
+func (r *runInfra) Observers() []Observer {
+	return nil
+}
+
+// Rand is a part of IInfra interface implementation
+func (r *runInfra) Rand() *rand.Rand {
+	return r.rnd
+}
@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testBatchInfra is an in-memory IBatchInfra implementation
+type testBatchInfra struct {
+	testInfra
+	runs    int
+	workers int
+}
+
+func (i *testBatchInfra) Runs() int {
+	return i.runs
+}
+
+func (i *testBatchInfra) Workers() int {
+	return i.workers
+}
+
+func TestBatch_Run(t *testing.T) {
+	src := `A north=B east=C
+B south=A east=D
+C west=A north=D
+D west=B south=C
+`
+	run := func(workers int) BatchReport {
+		b, err := InitBatch(&testBatchInfra{
+			testInfra: testInfra{in: src, aliensCount: 2, seed: 7},
+			runs:      200,
+			workers:   workers,
+		})
+		if err != nil {
+			t.Fatalf("InitBatch() error = %v", err)
+		}
+		report, err := b.Run()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		return report
+	}
+	report := run(1)
+	if got := run(4); !reflect.DeepEqual(got, report) {
+		t.Errorf("Run() depends on the number of workers: %+v, want %+v", got, report)
+	}
+	if report.Runs != 200 || len(report.DestructionProbability) != 4 {
+		t.Fatalf("Run() = %+v", report)
+	}
+	runs, destroyed := 0, 0.0
+	for survivors, n := range report.Survivors {
+		runs += n
+		destroyed += float64((4 - survivors) * n)
+	}
+	if runs != report.Runs {
+		t.Errorf("Run() survivors cover %d runs, want %d", runs, report.Runs)
+	}
+	probabilities := 0.0
+	for _, p := range report.DestructionProbability {
+		probabilities += p
+	}
+	if diff := probabilities*float64(report.Runs) - destroyed; diff > 1e-6 || diff < -1e-6 {
+		t.Errorf("Run() destruction probabilities sum to %v, want %v", probabilities, destroyed/float64(report.Runs))
+	}
+	if report.Fights > 0 && report.MeanLastDestructionTick <= 0 {
+		t.Errorf("Run() mean last destruction tick = %v", report.MeanLastDestructionTick)
+	}
+}
+
+func TestInitBatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		infra *testBatchInfra
+	}{
+		{
+			name:  "No runs",
+			infra: &testBatchInfra{testInfra: testInfra{in: "A north=B\n", aliensCount: 1}},
+		},
+		{
+			name:  "Too many aliens",
+			infra: &testBatchInfra{testInfra: testInfra{in: "A north=B\n", aliensCount: 3}, runs: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := InitBatch(tt.infra); err == nil {
+				t.Errorf("InitBatch() error = nil, want error")
+			}
+		})
+	}
+}
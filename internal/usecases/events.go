@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"strings"
+)
+
+// EventKind is an integer value that identifies the kind of the Scenario Event.
+type EventKind uint
+
+// Enumeration of all Event kinds emitted by the Scenario
+const (
+	AlienSeeded EventKind = iota
+	AlienMoved
+	AlienTrapped
+	CityDestroyed
+	MoveBudgetExhausted
+	ScenarioFinished
+	RoadDestroyed
+	AlienStayed
+)
+
+// eventKindNames links EventKind enumeration to their names
+var eventKindNames = map[EventKind]string{
+	AlienSeeded:         "seed",
+	AlienMoved:          "move",
+	AlienTrapped:        "trap",
+	CityDestroyed:       "destruction",
+	MoveBudgetExhausted: "exhausted",
+	ScenarioFinished:    "finish",
+	RoadDestroyed:       "road-destruction",
+	AlienStayed:         "stay",
+}
+
+// String is a part of the Stringer interface implementation for the EventKind
+func (k EventKind) String() string {
+	return eventKindNames[k]
+}
+
+// Event describes a single thing happened during the Scenario execution.
+//
+// Tick is the number of Alien moves made since the start of the Scenario. Alien and City are the actor
+// of the Event and the City where it happened. Direction is set for AlienMoved and holds direction
+// of the road Alien took to reach the City. Aliens lists every Alien taking part in the CityDestroyed fight.
+//
+// RoadDestroyed is emitted when Aliens swapping Cities meet on the road between City and To.
+// Direction holds direction of the road from the City.
+type Event struct {
+	Kind      EventKind
+	Tick      int
+	Alien     domain.Alien
+	City      string
+	To        string
+	Direction domain.Direction
+	Aliens    []domain.Alien
+}
+
+// Observer is the subscriber of the Scenario Events.
+type Observer interface {
+	Notify(e Event)
+}
+
+// ObserverFunc is an adapter to allow the use of ordinary functions as Observer.
+type ObserverFunc func(e Event)
+
+// Notify is the Observer interface implementation. Calls f(e).
+func (f ObserverFunc) Notify(e Event) {
+	f(e)
+}
+
+// DestructionLog creates Observer which reports every destroyed City and road with the given log function.
+// Aliens are named according to the registry.
+func DestructionLog(log func(format string, a ...any), registry *domain.AlienRegistry) Observer {
+	return ObserverFunc(func(e Event) {
+		switch e.Kind {
+		case CityDestroyed:
+			log("%s has been destroyed by %s\n", e.City, listAliens(e.Aliens, registry))
+		case RoadDestroyed:
+			log("road between %s and %s has been destroyed by %s\n", e.City, e.To, listAliens(e.Aliens, registry))
+		}
+	})
+}
+
+// listAliens joins Alien names into the human-readable list, e.g. "Zorg, Kang and Big Bob"
+func listAliens(aliens []domain.Alien, registry *domain.AlienRegistry) string {
+	names := make([]string, len(aliens))
+	for i, alien := range aliens {
+		names[i] = registry.Name(alien)
+	}
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"testing"
+)
+
+func TestDestructionLog(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		names map[domain.Alien]string
+		want  string
+	}{
+		{
+			name:  "Move event is ignored",
+			event: Event{Kind: AlienMoved, Alien: 1, City: "Foo", Direction: domain.North},
+			want:  "",
+		},
+		{
+			name:  "City destroyed",
+			event: Event{Kind: CityDestroyed, Alien: 1, City: "Foo", Aliens: []domain.Alien{0, 1}},
+			want:  "Foo has been destroyed by alien 1 and alien 2\n",
+		},
+		{
+			name:  "City destroyed by three aliens",
+			event: Event{Kind: CityDestroyed, Alien: 4, City: "Foo", Aliens: []domain.Alien{4, 0, 2}},
+			want:  "Foo has been destroyed by alien 5, alien 1 and alien 3\n",
+		},
+		{
+			name:  "Road destroyed",
+			event: Event{Kind: RoadDestroyed, Alien: 1, City: "Foo", To: "Bar", Direction: domain.North, Aliens: []domain.Alien{1, 0}},
+			want:  "road between Foo and Bar has been destroyed by alien 2 and alien 1\n",
+		},
+		{
+			name:  "Named aliens",
+			event: Event{Kind: CityDestroyed, Alien: 1, City: "Foo", Aliens: []domain.Alien{0, 1}},
+			names: map[domain.Alien]string{0: "Zorg", 1: "Kang"},
+			want:  "Foo has been destroyed by Zorg and Kang\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			names := []string{"alien 1", "alien 2", "alien 3", "alien 4", "alien 5"}
+			for alien, name := range tt.names {
+				names[alien] = name
+			}
+			registry, err := domain.NewAlienRegistry(names)
+			if err != nil {
+				t.Fatalf("NewAlienRegistry() error = %v", err)
+			}
+			o := DestructionLog(func(format string, a ...any) {
+				got += fmt.Sprintf(format, a...)
+			}, registry)
+			o.Notify(tt.event)
+			if got != tt.want {
+				t.Errorf("DestructionLog() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventKind_String(t *testing.T) {
+	tests := []struct {
+		kind EventKind
+		want string
+	}{
+		{AlienSeeded, "seed"},
+		{AlienMoved, "move"},
+		{AlienTrapped, "trap"},
+		{CityDestroyed, "destruction"},
+		{MoveBudgetExhausted, "exhausted"},
+		{ScenarioFinished, "finish"},
+		{RoadDestroyed, "road-destruction"},
+		{AlienStayed, "stay"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.kind.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,74 +4,214 @@ Package usecases contains all available application logic scenarios.
 package usecases
 
 import (
+	"errors"
 	"fmt"
 	"github.com/zippunov/alien-invasion/internal/domain"
 	"github.com/zippunov/alien-invasion/internal/encoding"
 	"io"
 	"math/rand"
+	"sort"
 )
 
 // IInfra interface specifies all required functionality from the application environment.
 // Varios IInfra can be injected into Scenario in order to provide better testing.
 type IInfra interface {
 	In() io.Reader
+	InFormat() encoding.Format
+	Symmetric() bool
+	Simultaneous() bool
 	Out() io.Writer
+	OutFormat() encoding.Format
+	Dot() io.Writer
 	AliensCount() int
-	Log() func(format string, a ...any)
+	MoveBudget(alien domain.Alien) int
+	TickLimit() int
+	StartCity(alien domain.Alien) string
+	Placement() Placement
+	Population(city string) int
+	Registry() *domain.AlienRegistry
+	Strategy(alien domain.Alien) MovementStrategy
+	Observers() []Observer
+	Rand() *rand.Rand
 }
 
 // Scenario is the usecase where Alien Invasion scenario is getting executed.
 //
-// Aliens are represented by int number from 0 to aliensCount-1
+// # Aliens are represented by int number from 0 to aliensCount-1
+//
+// By default Aliens move one at a time and every fight is resolved before the next move.
+// In simultaneous mode Aliens move round by round: every Alien chooses a road, all of them move at once,
+// and then fights are resolved.
 type Scenario struct {
 	out         io.Writer
+	outFormat   encoding.Format               // format of the resulting Map
+	layout      *encoding.Layout              // layout of the Map text source kept by the resulting Map
+	dot         io.Writer                     // optional destination of the Graphviz Map graphs
+	destroyed   []string                      // names of the destroyed Cities in order of destruction
 	worldMap    domain.Map                    // Cities graph
 	aliensCount int                           // start Aliens count
 	aliens      map[domain.Alien]*domain.City // maps each alien to a single City
+	starts      map[domain.Alien]*domain.City // fixed starting City of the aliens, others start in random Cities
+	placement   Placement                     // policy of the random starting Cities choice
+	registry    *domain.AlienRegistry         // names of the aliens
+	population  map[string]int                // population of every City, set for PlaceWeighted only
 	movesLeft   []int                         // holds number of moves left for each alien by the Alien integer id.
-	log         func(format string, a ...any) // logger function
+	strategies  []MovementStrategy            // holds movement strategy of each alien by the Alien integer id.
+	observers   []Observer                    // subscribers of the Scenario events
+	stats       statsCollector                // summary of the Scenario run
+	tick        int                           // number of Alien moves made so far
+	tickLimit   int                           // number of Alien moves which ends the Scenario, 0 means no limit
+	rounds      bool                          // Aliens move simultaneously round by round
+	rnd         *rand.Rand                    // source of every random choice made by the Scenario
 }
 
 // InitScenario scenario initialization with provided infrastructure
 func InitScenario(infra IInfra) (Scenario, error) {
-	m := domain.Map{}
-	if err := encoding.UnmarshalTxt(infra.In(), m); err != nil {
+	m, layout, err := loadMap(infra)
+	if err != nil {
 		return Scenario{}, err
 	}
+	return newScenario(infra, m, layout)
+}
+
+// mapSource is the part of the infrastructure the Map is loaded from, shared by every usecase reading the Map
+type mapSource interface {
+	In() io.Reader
+	InFormat() encoding.Format
+	Symmetric() bool
+}
+
+// loadMap reads the Map and the layout of its text source from the infrastructure input,
+// missing reverse roads are inferred if required
+func loadMap(infra mapSource) (domain.Map, *encoding.Layout, error) {
+	m := domain.Map{}
+	layout, err := encoding.Unmarshal(infra.In(), m, infra.InFormat())
+	if err != nil {
+		return nil, nil, err
+	}
+	if infra.Symmetric() {
+		if err := m.Symmetrize(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return m, layout, nil
+}
+
+// newScenario creates Scenario on the given Map with provided infrastructure. The Scenario owns the Map.
+// The resulting Map is written with the layout of the Map text source, nil layout is empty.
+// Alien occupancy stored in the Map source, e.g. the JSON output of the previous run, is cleared:
+// the Scenario places its own Aliens.
+func newScenario(infra IInfra, m domain.Map, layout *encoding.Layout) (Scenario, error) {
+	for _, city := range m {
+		city.Aliens = nil
+	}
 	n := infra.AliensCount()
-	if len(m) < n {
+	placement := infra.Placement()
+	if placement == PlaceUnique && len(m) < n {
 		return Scenario{}, fmt.Errorf("aliens count is greater than number of  cities (%d)", len(m))
 	}
+	if len(m) == 0 && n > 0 {
+		return Scenario{}, errors.New("map has no cities")
+	}
+	var population map[string]int
+	if placement == PlaceWeighted {
+		population = make(map[string]int, len(m))
+		total := 0
+		for name := range m {
+			population[name] = infra.Population(name)
+			total += population[name]
+		}
+		if total == 0 {
+			return Scenario{}, errors.New("total population of the cities is 0")
+		}
+	}
 
 	aliens := make(map[domain.Alien]*domain.City, n)
+	starts := map[domain.Alien]*domain.City{}
+	registry := infra.Registry()
+	taken := map[*domain.City]domain.Alien{}
 	movesLeft := make([]int, n)
+	strategies := make([]MovementStrategy, n)
 	for i := 0; i < n; i++ {
 		alien := domain.Alien(i)
 		aliens[alien] = nil
-		movesLeft[alien] = 10000
+		movesLeft[alien] = infra.MoveBudget(alien)
+		strategies[alien] = infra.Strategy(alien)
+		name := infra.StartCity(alien)
+		if len(name) == 0 {
+			continue
+		}
+		city, ok := m[name]
+		if !ok {
+			return Scenario{}, fmt.Errorf("start city %s of alien %s is not found", name, registry.Name(alien))
+		}
+		if other, ok := taken[city]; ok && placement == PlaceUnique {
+			return Scenario{}, fmt.Errorf("aliens %s and %s can not start in the same city %s",
+				registry.Name(other), registry.Name(alien), name)
+		}
+		starts[alien], taken[city] = city, alien
 	}
 	return Scenario{
 		out:         infra.Out(),
+		outFormat:   infra.OutFormat(),
+		layout:      layout,
+		dot:         infra.Dot(),
 		aliensCount: n,
 		worldMap:    m,
 		aliens:      aliens,
+		starts:      starts,
+		placement:   placement,
+		registry:    registry,
+		population:  population,
 		movesLeft:   movesLeft,
-		log:         infra.Log(),
+		strategies:  strategies,
+		observers:   infra.Observers(),
+		tickLimit:   infra.TickLimit(),
+		rounds:      infra.Simultaneous(),
+		rnd:         infra.Rand(),
 	}, nil
 }
 
 // Run executes the Usecase
 func (s *Scenario) Run() error {
 	s.seedAliens()
+	if s.dot != nil {
+		opts := encoding.DOTOptions{Name: "before", Highlight: true, Aliens: s.registry}
+		if err := encoding.MarshalDOT(s.dot, s.worldMap, opts); err != nil {
+			return err
+		}
+	}
+	if s.rounds {
+		s.runRounds()
+	} else {
+		s.runSequential()
+	}
+	s.emit(Event{Kind: ScenarioFinished})
+	if s.dot != nil {
+		opts := encoding.DOTOptions{Name: "after", Destroyed: s.destroyed, Highlight: true, Aliens: s.registry}
+		if err := encoding.MarshalDOT(s.dot, s.worldMap, opts); err != nil {
+			return err
+		}
+	}
+	// Output resulting Map
+	return encoding.Marshal(s.out, s.worldMap, s.outFormat, s.layout)
+}
+
+// runSequential moves Aliens one at a time.
+// Loop ends when no Alien can move or the tick limit is reached
+func (s *Scenario) runSequential() {
 	// queue of every alien with moves left randomized
 	q := s.aliensQueue()
 	// Main loop
 	// - pull Alien from the queue
-	// - move Alien in random available direction
+	// - move Alien in direction chosen by its strategy
 	// - destroy city if conditions met
 	// - mark Alien moved
 	for len(q) > 0 {
 		for _, alien := range q {
+			if s.tickLimitReached(0) {
+				return
+			}
 			if _, ok := s.aliens[alien]; !ok {
 				continue
 			}
@@ -80,31 +220,153 @@ func (s *Scenario) Run() error {
 			}
 			newCity := s.moveAlien(alien)
 			if newCity == nil {
-				s.movesLeft[alien] = 0
-			} else {
-				s.destroyCity(newCity)
+				continue
 			}
+			s.destroyCity(alien, newCity)
+			s.checkBudget(alien)
 		}
 		q = s.aliensQueue()
 	}
-	// Output resulting Map
-	return encoding.MarshalTxt(s.out, s.worldMap)
 }
 
-// seedAliens assings single Alien to a random City
+// plannedMove is the road chosen by the Alien for the current round
+type plannedMove struct {
+	alien     domain.Alien
+	from      *domain.City
+	direction domain.Direction
+	to        *domain.City
+}
+
+// runRounds moves Aliens simultaneously round by round. Every round consists of the following steps
+// - every Alien with moves left chooses direction with its strategy in the queue order
+// - Aliens moving towards each other along the roads between the same Cities meet and destroy these roads
+// - the rest of Aliens move at once
+// - every City occupied by 2 or more Aliens is destroyed
+// Loop ends when no Alien can move or the tick limit is reached. Tick limit can cut the last round short.
+func (s *Scenario) runRounds() {
+	for q := s.aliensQueue(); len(q) > 0 && !s.tickLimitReached(0); q = s.aliensQueue() {
+		moves := make([]plannedMove, 0, len(q))
+		for _, alien := range q {
+			if s.tickLimitReached(len(moves)) {
+				break
+			}
+			city := s.aliens[alien]
+			d, ok := s.chooseDirection(alien)
+			if !ok {
+				continue
+			}
+			moves = append(moves, plannedMove{alien: alien, from: city, direction: d, to: city.OutRoad[d]})
+		}
+		moves = s.resolveSwaps(moves)
+		for _, m := range moves {
+			s.relocate(m.alien, m.direction, m.to)
+		}
+		for _, m := range moves {
+			s.destroyCity(m.alien, m.to)
+		}
+		for _, m := range moves {
+			s.checkBudget(m.alien)
+		}
+	}
+}
+
+// resolveSwaps finds Aliens moving towards each other along the roads between the same Cities.
+// Such Aliens meet on the road and fight, the fight destroys the roads they took and every participant.
+// Returns moves of the Aliens not involved in any road fight.
+func (s *Scenario) resolveSwaps(moves []plannedMove) []plannedMove {
+	type pair struct{ from, to *domain.City }
+	byPair := map[pair][]plannedMove{}
+	for _, m := range moves {
+		byPair[pair{m.from, m.to}] = append(byPair[pair{m.from, m.to}], m)
+	}
+	fought := map[domain.Alien]bool{}
+	rest := make([]plannedMove, 0, len(moves))
+	for _, m := range moves {
+		if fought[m.alien] {
+			continue
+		}
+		oncoming, ok := byPair[pair{m.to, m.from}]
+		if !ok {
+			rest = append(rest, m)
+			continue
+		}
+		fight := append(append([]plannedMove(nil), byPair[pair{m.from, m.to}]...), oncoming...)
+		participants := make([]domain.Alien, 0, len(fight))
+		for _, f := range fight {
+			fought[f.alien] = true
+			participants = append(participants, f.alien)
+			f.from.Aliens = removeAlien(f.from.Aliens, f.alien)
+			delete(s.aliens, f.alien)
+			s.movesLeft[f.alien] = 0
+			s.tick++
+		}
+		s.emit(Event{
+			Kind:      RoadDestroyed,
+			Alien:     m.alien,
+			City:      m.from.Name,
+			To:        m.to.Name,
+			Direction: m.direction,
+			Aliens:    participants,
+		})
+		for _, f := range fight {
+			s.worldMap.DestroyRoad(f.from, f.direction)
+		}
+	}
+	return rest
+}
+
+// tickLimitReached reports whether the tick limit is reached after given number of pending moves
+func (s *Scenario) tickLimitReached(pending int) bool {
+	return s.tickLimit > 0 && s.tick+pending >= s.tickLimit
+}
+
+// trap marks Alien unable to leave its City
+func (s *Scenario) trap(alien domain.Alien) {
+	s.movesLeft[alien] = 0
+	s.emit(Event{Kind: AlienTrapped, Alien: alien, City: s.aliens[alien].Name})
+}
+
+// stay spends Alien move without leaving the City
+func (s *Scenario) stay(alien domain.Alien) {
+	s.movesLeft[alien] -= 1
+	s.tick++
+	s.emit(Event{Kind: AlienStayed, Alien: alien, City: s.aliens[alien].Name})
+	s.checkBudget(alien)
+}
+
+// checkBudget reports surviving Alien which has no moves left
+func (s *Scenario) checkBudget(alien domain.Alien) {
+	if city, ok := s.aliens[alien]; ok && s.movesLeft[alien] == 0 {
+		s.emit(Event{Kind: MoveBudgetExhausted, Alien: alien, City: city.Name})
+	}
+}
+
+// seedAliens assings single Alien to its starting City or to a random City chosen according to the placement
+// policy. With PlaceStackFight every City occupied by 2 or more Aliens is destroyed right after the placement.
 func (s *Scenario) seedAliens() {
-	cities := s.worldMap.ListCities()
-	rand.Shuffle(len(cities), func(i, j int) {
-		cities[i], cities[j] = cities[j], cities[i]
-	})
+	pick := s.cityPicker()
 	for i := 0; i < s.aliensCount; i++ {
 		alien := domain.Alien(i)
-		s.aliens[alien] = cities[i]
-		s.aliens[alien].Aliens = append(s.aliens[alien].Aliens, domain.Alien(i))
+		city, ok := s.starts[alien]
+		if !ok {
+			city = pick()
+		}
+		s.aliens[alien] = city
+		city.Aliens = append(city.Aliens, alien)
+		s.emit(Event{Kind: AlienSeeded, Alien: alien, City: city.Name})
+	}
+	if s.placement != PlaceStackFight {
+		return
+	}
+	for _, city := range s.worldMap.ListCities() {
+		if len(city.Aliens) > 1 {
+			s.destroyCity(city.Aliens[len(city.Aliens)-1], city)
+		}
 	}
 }
 
 // aliensQueue filters all Aliens that able to make a move and returns filtered Aliens in random order.
+// Aliens are sorted before shuffling, so the order depends on the random source only.
 func (s *Scenario) aliensQueue() []domain.Alien {
 	queue := make([]domain.Alien, 0, len(s.aliens))
 	for alien := range s.aliens {
@@ -112,38 +374,89 @@ func (s *Scenario) aliensQueue() []domain.Alien {
 			queue = append(queue, alien)
 		}
 	}
-	rand.Shuffle(len(queue), func(i, j int) {
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i] < queue[j]
+	})
+	s.rnd.Shuffle(len(queue), func(i, j int) {
 		queue[i], queue[j] = queue[j], queue[i]
 	})
 	return queue
 }
 
-// moveAlien executed single Alien move. The move Direction os randomly chosen among available out-roads in the City
+// moveAlien executed single Alien move. The move Direction is chosen by the Alien strategy.
+// Returns nil if Alien did not leave the City.
 func (s *Scenario) moveAlien(alien domain.Alien) *domain.City {
-	city := s.aliens[alien]
-	directions := city.Directions()
-	dirCount := len(directions)
-	if dirCount == 0 {
+	d, ok := s.chooseDirection(alien)
+	if !ok {
 		return nil
 	}
-	d := directions[rand.Intn(dirCount)]
-	nextCity := city.OutRoad[d]
+	nextCity := s.aliens[alien].OutRoad[d]
+	s.relocate(alien, d, nextCity)
+	return nextCity
+}
+
+// chooseDirection asks the Alien strategy for the out-road of the Alien City. Alien is trapped if there are
+// no roads out of the City and stays if the strategy decides so. Returns (0, false) in both cases.
+func (s *Scenario) chooseDirection(alien domain.Alien) (domain.Direction, bool) {
+	city := s.aliens[alien]
+	if len(city.OutRoad) == 0 {
+		s.trap(alien)
+		return 0, false
+	}
+	d, ok := s.strategies[alien].Choose(alien, city, s.rnd)
+	if !ok {
+		s.stay(alien)
+	}
+	return d, ok
+}
+
+// relocate moves Alien along the road in given Direction to the next City. Other occupants of both Cities stay in place.
+func (s *Scenario) relocate(alien domain.Alien, d domain.Direction, nextCity *domain.City) {
+	city := s.aliens[alien]
+	city.Aliens = removeAlien(city.Aliens, alien)
 	nextCity.Aliens = append(nextCity.Aliens, alien)
-	city.Aliens = city.Aliens[:0]
 	s.aliens[alien] = nextCity
 	s.movesLeft[alien] -= 1
-	return nextCity
+	s.tick++
+	s.emit(Event{Kind: AlienMoved, Alien: alien, City: nextCity.Name, Direction: d})
 }
 
-// destroyCity removes City and occupying Aliens from the Map if there are 2 Aliens in the City
-func (s *Scenario) destroyCity(city *domain.City) {
+// destroyCity removes City and occupying Aliens from the Map if there are 2 or more Aliens in the City.
+// alien is the one whose move triggered the fight.
+func (s *Scenario) destroyCity(alien domain.Alien, city *domain.City) {
 	if len(city.Aliens) < 2 {
 		return
 	}
-	s.log("%s has been destroyed by alien %d and alien %d\n", city.Name, city.Aliens[0]+1, city.Aliens[1]+1)
+	s.emit(Event{
+		Kind:   CityDestroyed,
+		Alien:  alien,
+		City:   city.Name,
+		Aliens: append([]domain.Alien(nil), city.Aliens...),
+	})
 	for _, alien := range city.Aliens {
 		delete(s.aliens, alien)
 		s.movesLeft[alien] = 0
 	}
 	s.worldMap.DestroyCity(city)
+	s.destroyed = append(s.destroyed, city.Name)
+	city.Aliens = nil
+}
+
+// removeAlien deletes Alien from the list keeping order of the others
+func removeAlien(aliens []domain.Alien, alien domain.Alien) []domain.Alien {
+	for i, a := range aliens {
+		if a == alien {
+			return append(aliens[:i:i], aliens[i+1:]...)
+		}
+	}
+	return aliens
+}
+
+// emit stamps Event with the current tick, adds it to the Stats and notifies every Scenario observer
+func (s *Scenario) emit(e Event) {
+	e.Tick = s.tick
+	s.stats.Notify(e)
+	for _, o := range s.observers {
+		o.Notify(e)
+	}
 }
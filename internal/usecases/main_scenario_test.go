@@ -1,9 +1,14 @@
 package usecases
 
 import (
+	"bytes"
+	"fmt"
 	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/encoding"
 	"io"
+	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -40,7 +45,7 @@ func TestScenario_Run(t *testing.T) {
 		aliensCount int
 		aliens      map[domain.Alien]*domain.City
 		movesLeft   []int
-		log         func(format string, a ...any)
+		observers   []Observer
 	}
 	tests := []struct {
 		name    string
@@ -57,7 +62,7 @@ func TestScenario_Run(t *testing.T) {
 				aliensCount: tt.fields.aliensCount,
 				aliens:      tt.fields.aliens,
 				movesLeft:   tt.fields.movesLeft,
-				log:         tt.fields.log,
+				observers:   tt.fields.observers,
 			}
 			if err := s.Run(); (err != nil) != tt.wantErr {
 				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
@@ -73,7 +78,7 @@ func TestScenario_aliensQueue(t *testing.T) {
 		aliensCount int
 		aliens      map[domain.Alien]*domain.City
 		movesLeft   []int
-		log         func(format string, a ...any)
+		observers   []Observer
 	}
 	tests := []struct {
 		name   string
@@ -90,7 +95,7 @@ func TestScenario_aliensQueue(t *testing.T) {
 				aliensCount: tt.fields.aliensCount,
 				aliens:      tt.fields.aliens,
 				movesLeft:   tt.fields.movesLeft,
-				log:         tt.fields.log,
+				observers:   tt.fields.observers,
 			}
 			if got := s.aliensQueue(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("aliensQueue() = %v, want %v", got, tt.want)
@@ -106,10 +111,11 @@ func TestScenario_destroyCity(t *testing.T) {
 		aliensCount int
 		aliens      map[domain.Alien]*domain.City
 		movesLeft   []int
-		log         func(format string, a ...any)
+		observers   []Observer
 	}
 	type args struct {
-		city *domain.City
+		alien domain.Alien
+		city  *domain.City
 	}
 	tests := []struct {
 		name   string
@@ -126,9 +132,9 @@ func TestScenario_destroyCity(t *testing.T) {
 				aliensCount: tt.fields.aliensCount,
 				aliens:      tt.fields.aliens,
 				movesLeft:   tt.fields.movesLeft,
-				log:         tt.fields.log,
+				observers:   tt.fields.observers,
 			}
-			s.destroyCity(tt.args.city)
+			s.destroyCity(tt.args.alien, tt.args.city)
 		})
 	}
 }
@@ -140,7 +146,7 @@ func TestScenario_moveAlien(t *testing.T) {
 		aliensCount int
 		aliens      map[domain.Alien]*domain.City
 		movesLeft   []int
-		log         func(format string, a ...any)
+		observers   []Observer
 	}
 	type args struct {
 		alien domain.Alien
@@ -161,7 +167,7 @@ func TestScenario_moveAlien(t *testing.T) {
 				aliensCount: tt.fields.aliensCount,
 				aliens:      tt.fields.aliens,
 				movesLeft:   tt.fields.movesLeft,
-				log:         tt.fields.log,
+				observers:   tt.fields.observers,
 			}
 			if got := s.moveAlien(tt.args.alien); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("moveAlien() = %v, want %v", got, tt.want)
@@ -177,7 +183,7 @@ func TestScenario_seedAliens(t *testing.T) {
 		aliensCount int
 		aliens      map[domain.Alien]*domain.City
 		movesLeft   []int
-		log         func(format string, a ...any)
+		observers   []Observer
 	}
 	tests := []struct {
 		name   string
@@ -193,9 +199,426 @@ func TestScenario_seedAliens(t *testing.T) {
 				aliensCount: tt.fields.aliensCount,
 				aliens:      tt.fields.aliens,
 				movesLeft:   tt.fields.movesLeft,
-				log:         tt.fields.log,
+				observers:   tt.fields.observers,
+			}
+			s.seedAliens()
+		})
+	}
+}
+
+// testInfra is an in-memory IInfra implementation
+type testInfra struct {
+	in           string
+	inFormat     encoding.Format
+	out          *bytes.Buffer
+	aliensCount  int
+	logs         *bytes.Buffer
+	seed         int64
+	budgets      map[domain.Alien]int
+	tickLimit    int
+	simultaneous bool
+	strategies   map[domain.Alien]MovementStrategy
+	starts       map[domain.Alien]string
+	placement    Placement
+	population   map[string]int
+}
+
+func (i *testInfra) In() io.Reader {
+	return strings.NewReader(i.in)
+}
+
+func (i *testInfra) InFormat() encoding.Format {
+	return i.inFormat
+}
+
+func (i *testInfra) Symmetric() bool {
+	return false
+}
+
+func (i *testInfra) Out() io.Writer {
+	return i.out
+}
+
+func (i *testInfra) OutFormat() encoding.Format {
+	return encoding.Txt
+}
+
+func (i *testInfra) Dot() io.Writer {
+	return nil
+}
+
+func (i *testInfra) AliensCount() int {
+	return i.aliensCount
+}
+
+func (i *testInfra) Simultaneous() bool {
+	return i.simultaneous
+}
+
+func (i *testInfra) MoveBudget(alien domain.Alien) int {
+	if budget, ok := i.budgets[alien]; ok {
+		return budget
+	}
+	return 10000
+}
+
+func (i *testInfra) Strategy(alien domain.Alien) MovementStrategy {
+	if strategy, ok := i.strategies[alien]; ok {
+		return strategy
+	}
+	return Uniform()
+}
+
+func (i *testInfra) StartCity(alien domain.Alien) string {
+	return i.starts[alien]
+}
+
+func (i *testInfra) Placement() Placement {
+	return i.placement
+}
+
+func (i *testInfra) Population(city string) int {
+	if n, ok := i.population[city]; ok {
+		return n
+	}
+	return 1
+}
+
+func (i *testInfra) Registry() *domain.AlienRegistry {
+	names := make([]string, i.aliensCount)
+	for n := range names {
+		names[n] = fmt.Sprintf("alien %d", n+1)
+	}
+	registry, _ := domain.NewAlienRegistry(names)
+	return registry
+}
+
+func (i *testInfra) TickLimit() int {
+	return i.tickLimit
+}
+
+func (i *testInfra) Observers() []Observer {
+	return []Observer{
+		DestructionLog(func(format string, a ...any) {
+			_, _ = fmt.Fprintf(i.logs, format, a...)
+		}, i.Registry()),
+	}
+}
+
+func (i *testInfra) Rand() *rand.Rand {
+	return rand.New(rand.NewSource(i.seed))
+}
+
+const testMap = `B south=Y north=G
+C west=I east=O south=S north=Z
+G east=J west=B south=N north=Y
+I south=C east=J
+J north=X west=G
+N west=B north=I east=Z
+O south=B west=Z north=X
+S north=N east=I
+X west=C south=N
+Y west=S east=C north=J
+Z north=Y west=J
+`
+
+func TestScenario_RunSeeded(t *testing.T) {
+	tests := []struct {
+		name        string
+		aliensCount int
+		seed        int64
+	}{
+		{
+			name:        "Few aliens",
+			aliensCount: 2,
+			seed:        1,
+		},
+		{
+			name:        "Half of cities",
+			aliensCount: 6,
+			seed:        42,
+		},
+		{
+			name:        "Alien in every city",
+			aliensCount: 11,
+			seed:        -7,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var outs, logs [2]string
+			for i := range outs {
+				infra := &testInfra{
+					in:          testMap,
+					out:         &bytes.Buffer{},
+					aliensCount: tt.aliensCount,
+					logs:        &bytes.Buffer{},
+					seed:        tt.seed,
+				}
+				s, err := InitScenario(infra)
+				if err != nil {
+					t.Fatalf("InitScenario() error = %v", err)
+				}
+				if err := s.Run(); err != nil {
+					t.Fatalf("Run() error = %v", err)
+				}
+				outs[i], logs[i] = infra.out.String(), infra.logs.String()
+			}
+			if outs[0] != outs[1] {
+				t.Errorf("Run() resulting maps differ for the same seed:\n%v\n%v", outs[0], outs[1])
+			}
+			if logs[0] != logs[1] {
+				t.Errorf("Run() destruction logs differ for the same seed:\n%v\n%v", logs[0], logs[1])
+			}
+		})
+	}
+}
+
+func TestScenario_RunEvents(t *testing.T) {
+	var events []Event
+	s, err := InitScenario(&testInfra{
+		in:          testMap,
+		out:         &bytes.Buffer{},
+		aliensCount: 6,
+		logs:        &bytes.Buffer{},
+		seed:        42,
+	})
+	if err != nil {
+		t.Fatalf("InitScenario() error = %v", err)
+	}
+	s.observers = append(s.observers, ObserverFunc(func(e Event) {
+		events = append(events, e)
+	}))
+	if err := s.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	counts := map[EventKind]int{}
+	for _, e := range events {
+		counts[e.Kind]++
+	}
+	if counts[AlienSeeded] != 6 {
+		t.Errorf("Run() seeded %d aliens, want %d", counts[AlienSeeded], 6)
+	}
+	if killed := 2 * counts[CityDestroyed]; killed+counts[AlienTrapped]+counts[MoveBudgetExhausted] != 6 {
+		t.Errorf("Run() every alien must be either killed, trapped or exhausted, got %v", counts)
+	}
+	last := events[len(events)-1]
+	if last.Kind != ScenarioFinished || last.Tick != counts[AlienMoved] {
+		t.Errorf("Run() last event = %+v, want finish at tick %d", last, counts[AlienMoved])
+	}
+}
+
+func TestScenario_RunLimits(t *testing.T) {
+	tests := []struct {
+		name      string
+		budgets   map[domain.Alien]int
+		tickLimit int
+		check     func(t *testing.T, moves map[domain.Alien]int, finish Event)
+	}{
+		{
+			name:    "per-alien budget",
+			budgets: map[domain.Alien]int{0: 1, 1: 0, 2: 2, 3: 1},
+			check: func(t *testing.T, moves map[domain.Alien]int, _ Event) {
+				for alien, budget := range map[domain.Alien]int{0: 1, 1: 0, 2: 2, 3: 1} {
+					if moves[alien] > budget {
+						t.Errorf("alien %d made %d moves, budget %d", alien, moves[alien], budget)
+					}
+				}
+			},
+		},
+		{
+			name:      "tick limit",
+			tickLimit: 3,
+			check: func(t *testing.T, moves map[domain.Alien]int, finish Event) {
+				total := 0
+				for _, n := range moves {
+					total += n
+				}
+				if total != 3 || finish.Tick != 3 {
+					t.Errorf("made %d moves, finished at tick %d, want 3", total, finish.Tick)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []Event
+			s, err := InitScenario(&testInfra{
+				in:          testMap,
+				out:         &bytes.Buffer{},
+				aliensCount: 4,
+				logs:        &bytes.Buffer{},
+				seed:        7,
+				budgets:     tt.budgets,
+				tickLimit:   tt.tickLimit,
+			})
+			if err != nil {
+				t.Fatalf("InitScenario() error = %v", err)
+			}
+			s.observers = append(s.observers, ObserverFunc(func(e Event) {
+				events = append(events, e)
+			}))
+			if err := s.Run(); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			moves := map[domain.Alien]int{}
+			for _, e := range events {
+				if e.Kind == AlienMoved {
+					moves[e.Alien]++
+				}
+			}
+			tt.check(t, moves, events[len(events)-1])
+		})
+	}
+}
+
+// placedScenario creates simultaneous Scenario over the text Map with Aliens placed into the given Cities
+func placedScenario(t *testing.T, txt string, cities ...string) (*Scenario, *[]Event) {
+	m := domain.Map{}
+	if err := encoding.UnmarshalTxt(strings.NewReader(txt), m); err != nil {
+		t.Fatalf("UnmarshalTxt() error = %v", err)
+	}
+	events := &[]Event{}
+	s := &Scenario{
+		out:         &bytes.Buffer{},
+		worldMap:    m,
+		aliensCount: len(cities),
+		aliens:      map[domain.Alien]*domain.City{},
+		movesLeft:   make([]int, len(cities)),
+		strategies:  make([]MovementStrategy, len(cities)),
+		rounds:      true,
+		rnd:         rand.New(rand.NewSource(1)),
+		observers: []Observer{ObserverFunc(func(e Event) {
+			*events = append(*events, e)
+		})},
+	}
+	for i, name := range cities {
+		alien := domain.Alien(i)
+		s.aliens[alien] = m[name]
+		m[name].Aliens = append(m[name].Aliens, alien)
+		s.movesLeft[alien] = 1
+		s.strategies[alien] = Uniform()
+	}
+	return s, events
+}
+
+func TestScenario_runRounds(t *testing.T) {
+	t.Run("three aliens fight", func(t *testing.T) {
+		s, events := placedScenario(t, "A east=D\nB north=D\nC west=D\n", "A", "B", "C")
+		s.runRounds()
+		var destroyed []Event
+		for _, e := range *events {
+			if e.Kind == CityDestroyed {
+				destroyed = append(destroyed, e)
 			}
+		}
+		if len(destroyed) != 1 || destroyed[0].City != "D" || len(destroyed[0].Aliens) != 3 {
+			t.Fatalf("runRounds() destruction events = %+v, want D destroyed by 3 aliens", destroyed)
+		}
+		if _, ok := s.worldMap["D"]; ok || len(s.aliens) != 0 {
+			t.Errorf("runRounds() D and every alien must be destroyed")
+		}
+	})
+	t.Run("aliens swap cities", func(t *testing.T) {
+		s, events := placedScenario(t, "A east=B\nB west=A north=C\nC south=B\n", "A", "B", "C")
+		s.rnd = rand.New(rand.NewSource(2))
+		s.runRounds()
+		var fight Event
+		for _, e := range *events {
+			if e.Kind == RoadDestroyed {
+				fight = e
+			}
+		}
+		if fight.Kind != RoadDestroyed || len(fight.Aliens) != 2 {
+			t.Fatalf("runRounds() events = %+v, want road destruction by 2 aliens", *events)
+		}
+		for _, c := range s.worldMap {
+			for _, to := range c.OutRoad {
+				if (c.Name == fight.City && to.Name == fight.To) || (c.Name == fight.To && to.Name == fight.City) {
+					t.Errorf("runRounds() road %s-%s must be destroyed", c.Name, to.Name)
+				}
+			}
+		}
+		if len(s.worldMap) != 3 || len(s.aliens) != 1 {
+			t.Errorf("runRounds() cities = %d, aliens = %d, want 3 cities and 1 alien", len(s.worldMap), len(s.aliens))
+		}
+	})
+}
+
+func TestScenario_StartCities(t *testing.T) {
+	tests := []struct {
+		name    string
+		starts  map[domain.Alien]string
+		wantErr bool
+	}{
+		{name: "Fixed and random cities", starts: map[domain.Alien]string{0: "B", 2: "Z"}},
+		{name: "Unknown city", starts: map[domain.Alien]string{1: "Nowhere"}, wantErr: true},
+		{name: "Same city", starts: map[domain.Alien]string{0: "B", 3: "B"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seeded []Event
+			s, err := InitScenario(&testInfra{
+				in:          testMap,
+				out:         &bytes.Buffer{},
+				aliensCount: 5,
+				logs:        &bytes.Buffer{},
+				seed:        3,
+				starts:      tt.starts,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InitScenario() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			s.observers = []Observer{ObserverFunc(func(e Event) {
+				seeded = append(seeded, e)
+			})}
 			s.seedAliens()
+			cities := map[string]bool{}
+			for _, e := range seeded {
+				cities[e.City] = true
+				if want, ok := tt.starts[e.Alien]; ok && e.City != want {
+					t.Errorf("alien %d starts in %s, want %s", e.Alien, e.City, want)
+				}
+			}
+			if len(seeded) != 5 || len(cities) != 5 {
+				t.Errorf("seedAliens() = %+v, want 5 aliens in distinct cities", seeded)
+			}
+		})
+	}
+}
+
+func TestScenario_RunStoredAliens(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "Unknown alien", in: `{"cities": [{"name": "A", "roads": {"north": "B"}, "aliens": [7]}, {"name": "B", "roads": {"south": "A"}}]}`},
+		{name: "Previous run output", in: `{"cities": [{"name": "A", "roads": {"north": "B"}, "aliens": [0]}, {"name": "B", "roads": {"south": "A"}}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := &bytes.Buffer{}
+			s, err := InitScenario(&testInfra{
+				in:          tt.in,
+				inFormat:    encoding.JSON,
+				out:         &bytes.Buffer{},
+				aliensCount: 1,
+				logs:        logs,
+				seed:        1,
+				budgets:     map[domain.Alien]int{0: 5},
+			})
+			if err != nil {
+				t.Fatalf("InitScenario() error = %v", err)
+			}
+			if err := s.Run(); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if logs.Len() != 0 || len(s.worldMap) != 2 {
+				t.Errorf("Run() the single alien destroyed a city: %q", logs.String())
+			}
 		})
 	}
 }
@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"sort"
+	"strings"
+)
+
+// Placement is an integer value that identifies the policy of the initial Aliens placement.
+type Placement uint
+
+// Enumeration of all placement policies
+const (
+	// PlaceUnique places every Alien into a distinct random City, number of Aliens is limited by number of Cities
+	PlaceUnique Placement = iota
+	// PlaceStackFight places Aliens into random Cities, Aliens sharing the City fight right after the placement
+	PlaceStackFight
+	// PlaceStackPeaceful places Aliens into random Cities, Aliens sharing the City fight only when
+	// another Alien moves into their City
+	PlaceStackPeaceful
+	// PlaceWeighted places Aliens into random Cities with probability proportional to the City population.
+	// Aliens sharing the City behave like with PlaceStackPeaceful.
+	PlaceWeighted
+)
+
+// placementMap links string names to the Placement enumeration
+var placementMap = map[string]Placement{
+	"unique":         PlaceUnique,
+	"stack-fight":    PlaceStackFight,
+	"stack-peaceful": PlaceStackPeaceful,
+	"weighted":       PlaceWeighted,
+}
+
+// placementNames links Placement enumeration to their names
+var placementNames = map[Placement]string{
+	PlaceUnique:        "unique",
+	PlaceStackFight:    "stack-fight",
+	PlaceStackPeaceful: "stack-peaceful",
+	PlaceWeighted:      "weighted",
+}
+
+// PlacementByName fetches Placement by its name.
+// Returns (0, false) if given name not found
+func PlacementByName(name string) (Placement, bool) {
+	result, ok := placementMap[strings.ToLower(name)]
+	return result, ok
+}
+
+// String is a part of the Stringer interface implementation for the Placement
+func (p Placement) String() string {
+	return placementNames[p]
+}
+
+// cityPicker returns function choosing starting City of the Alien without fixed start according to the placement
+// policy. Cities are listed sorted before any random choice, so the choice depends on the random source only.
+func (s *Scenario) cityPicker() func() *domain.City {
+	cities := s.worldMap.ListCities()
+	switch s.placement {
+	case PlaceStackFight, PlaceStackPeaceful:
+		return func() *domain.City {
+			return cities[s.rnd.Intn(len(cities))]
+		}
+	case PlaceWeighted:
+		// cumulative[i] is the total population of the cities[0..i]
+		cumulative := make([]int64, len(cities))
+		total := int64(0)
+		for i, city := range cities {
+			total += int64(s.population[city.Name])
+			cumulative[i] = total
+		}
+		return func() *domain.City {
+			x := s.rnd.Int63n(total)
+			return cities[sort.Search(len(cumulative), func(i int) bool {
+				return cumulative[i] > x
+			})]
+		}
+	}
+	taken := make(map[*domain.City]bool, len(s.starts))
+	for _, city := range s.starts {
+		taken[city] = true
+	}
+	free := cities[:0]
+	for _, city := range cities {
+		if !taken[city] {
+			free = append(free, city)
+		}
+	}
+	s.rnd.Shuffle(len(free), func(i, j int) {
+		free[i], free[j] = free[j], free[i]
+	})
+	return func() *domain.City {
+		city := free[0]
+		free = free[1:]
+		return city
+	}
+}
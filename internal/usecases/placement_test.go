@@ -0,0 +1,113 @@
+package usecases
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPlacementByName(t *testing.T) {
+	for name, want := range placementMap {
+		got, ok := PlacementByName(name)
+		if !ok || got != want || got.String() != name {
+			t.Errorf("PlacementByName(%s) = %v, %v", name, got, ok)
+		}
+	}
+	if _, ok := PlacementByName("random"); ok {
+		t.Errorf("PlacementByName(random) must fail")
+	}
+}
+
+func TestScenario_seedAliensPlacement(t *testing.T) {
+	tests := []struct {
+		name       string
+		placement  Placement
+		population map[string]int
+		aliens     int
+		wantErr    bool
+		check      func(t *testing.T, s *Scenario, events []Event)
+	}{
+		{
+			name:      "Unique limits aliens",
+			placement: PlaceUnique,
+			aliens:    20,
+			wantErr:   true,
+		},
+		{
+			name:      "Stack and fight",
+			placement: PlaceStackFight,
+			aliens:    40,
+			check: func(t *testing.T, s *Scenario, events []Event) {
+				killed := 0
+				for _, e := range events {
+					if e.Kind == CityDestroyed {
+						killed += len(e.Aliens)
+						if e.Tick != 0 {
+							t.Errorf("city %s destroyed at tick %d, want 0", e.City, e.Tick)
+						}
+					}
+				}
+				if killed == 0 || killed+len(s.aliens) != 40 {
+					t.Errorf("killed %d, survived %d, want 40 in total", killed, len(s.aliens))
+				}
+				for _, city := range s.worldMap {
+					if len(city.Aliens) > 1 {
+						t.Errorf("city %s keeps %d aliens", city.Name, len(city.Aliens))
+					}
+				}
+			},
+		},
+		{
+			name:      "Stack peacefully",
+			placement: PlaceStackPeaceful,
+			aliens:    40,
+			check: func(t *testing.T, s *Scenario, events []Event) {
+				if len(events) != 40 || len(s.aliens) != 40 {
+					t.Errorf("seedAliens() = %d events, %d aliens, want 40 seeded aliens", len(events), len(s.aliens))
+				}
+			},
+		},
+		{
+			name:       "Weighted by population",
+			placement:  PlaceWeighted,
+			population: map[string]int{"B": 0, "C": 0, "G": 0, "I": 0, "J": 0, "N": 0, "O": 0, "S": 0, "Y": 0, "Z": 0, "X": 5},
+			aliens:     40,
+			check: func(t *testing.T, s *Scenario, events []Event) {
+				if len(s.worldMap["X"].Aliens) != 40 {
+					t.Errorf("X has %d aliens, want 40", len(s.worldMap["X"].Aliens))
+				}
+			},
+		},
+		{
+			name:       "Empty population",
+			placement:  PlaceWeighted,
+			population: map[string]int{"B": 0, "C": 0, "G": 0, "I": 0, "J": 0, "N": 0, "O": 0, "S": 0, "Y": 0, "Z": 0, "X": 0},
+			aliens:     4,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := InitScenario(&testInfra{
+				in:          testMap,
+				out:         &bytes.Buffer{},
+				aliensCount: tt.aliens,
+				logs:        &bytes.Buffer{},
+				seed:        11,
+				placement:   tt.placement,
+				population:  tt.population,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InitScenario() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var events []Event
+			s.observers = []Observer{ObserverFunc(func(e Event) {
+				events = append(events, e)
+			})}
+			s.seedAliens()
+			tt.check(t, &s, events)
+		})
+	}
+}
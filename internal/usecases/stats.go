@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/graph"
+)
+
+// Stats is the summary of the Scenario run collected from the Scenario events
+type Stats struct {
+	CitiesDestroyed     int            // number of Cities destroyed by fights
+	CitiesSurvived      int            // number of Cities left on the Map
+	RoadsDestroyed      int            // number of road fights of Aliens swapping Cities
+	AliensKilled        int            // number of Aliens died in fights
+	AliensTrapped       int            // number of Aliens survived in Cities without out-roads
+	AliensExhausted     int            // number of Aliens survived with no moves left
+	AliensSurvived      int            // number of Aliens survived with moves left, e.g. stopped by the tick limit
+	TotalMoves          int            // number of moves made by all Aliens, stays are not counted
+	Ticks               int            // tick of the Scenario end
+	LastDestructionTick int            // tick of the last City or road destruction, 0 if nothing was destroyed
+	LargestComponent    int            // number of Cities in the largest surviving group of connected Cities
+	Destroyed           []string       // names of the destroyed Cities in order of destruction
+	Visits              map[string]int // number of Aliens arrivals to every City, including the initial placement
+}
+
+// statsCollector is the Observer accumulating Stats from the Scenario events.
+// Trapped and exhausted Aliens are counted at the end, as a later fight can kill them.
+type statsCollector struct {
+	stats Stats
+	idle  map[domain.Alien]EventKind // living Aliens unable to move: AlienTrapped or MoveBudgetExhausted
+}
+
+// Notify is the Observer interface implementation
+func (c *statsCollector) Notify(e Event) {
+	s := &c.stats
+	switch e.Kind {
+	case AlienSeeded, AlienMoved:
+		if s.Visits == nil {
+			s.Visits = map[string]int{}
+		}
+		s.Visits[e.City]++
+		if e.Kind == AlienMoved {
+			s.TotalMoves++
+		}
+	case AlienTrapped, MoveBudgetExhausted:
+		if c.idle == nil {
+			c.idle = map[domain.Alien]EventKind{}
+		}
+		c.idle[e.Alien] = e.Kind
+	case CityDestroyed:
+		s.CitiesDestroyed++
+		s.Destroyed = append(s.Destroyed, e.City)
+		c.kill(e)
+	case RoadDestroyed:
+		s.RoadsDestroyed++
+		c.kill(e)
+	case ScenarioFinished:
+		s.Ticks = e.Tick
+		s.AliensTrapped, s.AliensExhausted = 0, 0
+		for _, kind := range c.idle {
+			if kind == AlienTrapped {
+				s.AliensTrapped++
+			} else {
+				s.AliensExhausted++
+			}
+		}
+	}
+}
+
+// kill counts Aliens died in the fight, so they are not counted as trapped or exhausted
+func (c *statsCollector) kill(e Event) {
+	c.stats.AliensKilled += len(e.Aliens)
+	c.stats.LastDestructionTick = e.Tick
+	for _, alien := range e.Aliens {
+		delete(c.idle, alien)
+	}
+}
+
+// Stats returns summary of the Scenario run. It is complete after the Run only.
+func (s *Scenario) Stats() Stats {
+	stats := s.stats.stats
+	stats.Destroyed = append([]string(nil), stats.Destroyed...)
+	stats.Visits = make(map[string]int, len(s.stats.stats.Visits))
+	for city, n := range s.stats.stats.Visits {
+		stats.Visits[city] = n
+	}
+	stats.CitiesSurvived = len(s.worldMap)
+	stats.AliensSurvived = len(s.aliens) - stats.AliensTrapped - stats.AliensExhausted
+	stats.LargestComponent = largestComponent(s.worldMap)
+	return stats
+}
+
+// largestComponent returns number of Cities in the largest group of Cities connected by roads
+// regardless of the road direction
+func largestComponent(m domain.Map) int {
+	components := graph.New(m).WeaklyConnected()
+	if len(components) == 0 {
+		return 0
+	}
+	return len(components[0])
+}
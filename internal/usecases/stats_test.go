@@ -0,0 +1,95 @@
+package usecases
+
+import (
+	"bytes"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"strings"
+	"testing"
+)
+
+func TestScenario_Stats(t *testing.T) {
+	for _, simultaneous := range []bool{false, true} {
+		s, err := InitScenario(&testInfra{
+			in:           testMap,
+			out:          &bytes.Buffer{},
+			aliensCount:  6,
+			logs:         &bytes.Buffer{},
+			seed:         42,
+			simultaneous: simultaneous,
+		})
+		if err != nil {
+			t.Fatalf("InitScenario() error = %v", err)
+		}
+		if err := s.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		stats := s.Stats()
+		if stats.AliensKilled+stats.AliensTrapped+stats.AliensExhausted+stats.AliensSurvived != 6 {
+			t.Errorf("killed %d, trapped %d, exhausted %d, survived %d, want 6 aliens in total",
+				stats.AliensKilled, stats.AliensTrapped, stats.AliensExhausted, stats.AliensSurvived)
+		}
+		if stats.CitiesDestroyed+stats.CitiesSurvived != 11 || len(stats.Destroyed) != stats.CitiesDestroyed {
+			t.Errorf("destroyed %d, survived %d, want 11 cities in total", stats.CitiesDestroyed, stats.CitiesSurvived)
+		}
+		visits := 0
+		for _, n := range stats.Visits {
+			visits += n
+		}
+		if visits != 6+stats.TotalMoves {
+			t.Errorf("visits %d, want %d", visits, 6+stats.TotalMoves)
+		}
+		if stats.LastDestructionTick > stats.Ticks || stats.LargestComponent > stats.CitiesSurvived {
+			t.Errorf("inconsistent stats %+v", stats)
+		}
+	}
+}
+
+func TestScenario_StatsTrappedKilled(t *testing.T) {
+	// the first alien is trapped in B without out-roads, the second one comes from C through A and kills it
+	s, err := InitScenario(&testInfra{
+		in:          "A north=B\nC west=A\n",
+		out:         &bytes.Buffer{},
+		aliensCount: 2,
+		logs:        &bytes.Buffer{},
+		seed:        1,
+		starts:      map[domain.Alien]string{0: "B", 1: "C"},
+	})
+	if err != nil {
+		t.Fatalf("InitScenario() error = %v", err)
+	}
+	if err := s.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	stats := s.Stats()
+	if stats.AliensKilled+stats.AliensTrapped+stats.AliensExhausted+stats.AliensSurvived != 2 {
+		t.Errorf("killed %d, trapped %d, exhausted %d, survived %d, want 2 aliens in total",
+			stats.AliensKilled, stats.AliensTrapped, stats.AliensExhausted, stats.AliensSurvived)
+	}
+	if stats.AliensKilled != 2 || stats.AliensTrapped != 0 {
+		t.Errorf("killed %d, trapped %d, want the trapped alien killed by the arrival", stats.AliensKilled, stats.AliensTrapped)
+	}
+}
+
+func Test_largestComponent(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want int
+	}{
+		{name: "Empty map", src: "", want: 0},
+		{name: "Single road", src: "A north=B\n", want: 2},
+		{name: "Two groups", src: "A north=B\nC east=D\nD west=E\nF south=D\n", want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := domain.Map{}
+			if err := encoding.UnmarshalTxt(strings.NewReader(tt.src), m); err != nil {
+				t.Fatalf("UnmarshalTxt() error = %v", err)
+			}
+			if got := largestComponent(m); got != tt.want {
+				t.Errorf("largestComponent() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// MovementStrategy is the Alien AI choosing the road for every Alien move.
+// Strategy may keep the state between moves, so each Alien has its own MovementStrategy instance.
+type MovementStrategy interface {
+	// Choose returns Direction of the road out of the City taken by the Alien, or (0, false) if the Alien stays.
+	// City always has at least one out-road. Every random choice must be made with the given random source.
+	Choose(alien domain.Alien, city *domain.City, rnd *rand.Rand) (domain.Direction, bool)
+}
+
+// Names of the built-in strategies
+const (
+	StrategyUniform         = "uniform"
+	StrategyWeighted        = "weighted"
+	StrategyAvoidOccupied   = "avoid-occupied"
+	StrategySeekNearest     = "seek-nearest"
+	StrategyPreferUnvisited = "prefer-unvisited"
+	StrategyStayPut         = "stay-put"
+)
+
+// NewStrategy creates built-in MovementStrategy by its spec. Spec is the strategy name optionally
+// followed by colon and parameters:
+//
+//	uniform                     random road
+//	weighted:north=3,east=0.5   random road with probability proportional to the road weight
+//	avoid-occupied              random road to the City without Aliens
+//	seek-nearest                first road of the shortest path to the nearest City with Aliens
+//	prefer-unvisited            random road to the City never visited by the Alien
+//	stay-put:0.25               stays in the City with the given probability, random road otherwise
+//
+// Maps carry no road weights, so weights are given per Direction, roads of unlisted Directions weigh 1.
+// Strategies fall back to the random road when there are no roads matching their preference.
+func NewStrategy(spec string) (MovementStrategy, error) {
+	name, params, hasParams := strings.Cut(spec, ":")
+	switch strings.ToLower(name) {
+	case StrategyUniform:
+		return Uniform(), checkNoParams(name, hasParams)
+	case StrategyAvoidOccupied:
+		return AvoidOccupied(), checkNoParams(name, hasParams)
+	case StrategySeekNearest:
+		return SeekNearest(), checkNoParams(name, hasParams)
+	case StrategyPreferUnvisited:
+		return PreferUnvisited(), checkNoParams(name, hasParams)
+	case StrategyWeighted:
+		weights, err := parseWeights(params)
+		if err != nil {
+			return nil, err
+		}
+		return Weighted(weights), nil
+	case StrategyStayPut:
+		p, err := strconv.ParseFloat(params, 64)
+		if err != nil || p < 0 || p > 1 {
+			return nil, fmt.Errorf("invalid stay-put probability \"%s\", must be between 0 and 1", params)
+		}
+		return StayPut(p), nil
+	}
+	return nil, fmt.Errorf("unknown movement strategy %s", name)
+}
+
+// checkNoParams ensures that parameters are not given to the strategy which has none
+func checkNoParams(name string, hasParams bool) error {
+	if hasParams {
+		return fmt.Errorf("movement strategy %s has no parameters", name)
+	}
+	return nil
+}
+
+// parseWeights parses list of road weights, e.g. "north=3,east=0.5"
+func parseWeights(params string) (map[domain.Direction]float64, error) {
+	if len(params) == 0 {
+		return nil, errors.New("weighted strategy requires road weights, e.g. weighted:north=2")
+	}
+	weights := map[domain.Direction]float64{}
+	for _, item := range strings.Split(params, ",") {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid road weight \"%s\"", item)
+		}
+		d, ok := domain.DirectionByName(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid direction name %s", name)
+		}
+		w, err := strconv.ParseFloat(value, 64)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight \"%s\" of the %s roads", value, name)
+		}
+		weights[d] = w
+	}
+	return weights, nil
+}
+
+// uniform chooses every available road with equal probability
+type uniform struct{}
+
+// Uniform creates MovementStrategy choosing random road. It is the default strategy.
+func Uniform() MovementStrategy {
+	return uniform{}
+}
+
+// Choose is the MovementStrategy interface implementation
+func (uniform) Choose(_ domain.Alien, city *domain.City, rnd *rand.Rand) (domain.Direction, bool) {
+	return randomDirection(city.Directions(), rnd), true
+}
+
+// weighted chooses road with probability proportional to its weight
+type weighted struct {
+	weights map[domain.Direction]float64
+}
+
+// Weighted creates MovementStrategy choosing road with probability proportional to the weight of its Direction.
+// Directions missing in weights weigh 1.
+func Weighted(weights map[domain.Direction]float64) MovementStrategy {
+	return weighted{weights: weights}
+}
+
+// Choose is the MovementStrategy interface implementation
+func (s weighted) Choose(_ domain.Alien, city *domain.City, rnd *rand.Rand) (domain.Direction, bool) {
+	directions := city.Directions()
+	total := 0.0
+	for _, d := range directions {
+		total += s.weight(d)
+	}
+	if total == 0 {
+		return randomDirection(directions, rnd), true
+	}
+	x := rnd.Float64() * total
+	for _, d := range directions {
+		if x -= s.weight(d); x < 0 {
+			return d, true
+		}
+	}
+	return directions[len(directions)-1], true
+}
+
+// weight returns weight of the roads in given Direction
+func (s weighted) weight(d domain.Direction) float64 {
+	if w, ok := s.weights[d]; ok {
+		return w
+	}
+	return 1
+}
+
+// avoidOccupied prefers roads to the empty Cities
+type avoidOccupied struct{}
+
+// AvoidOccupied creates MovementStrategy choosing random road to the City without Aliens
+func AvoidOccupied() MovementStrategy {
+	return avoidOccupied{}
+}
+
+// Choose is the MovementStrategy interface implementation
+func (avoidOccupied) Choose(_ domain.Alien, city *domain.City, rnd *rand.Rand) (domain.Direction, bool) {
+	directions := city.Directions()
+	empty := make([]domain.Direction, 0, len(directions))
+	for _, d := range directions {
+		if len(city.OutRoad[d].Aliens) == 0 {
+			empty = append(empty, d)
+		}
+	}
+	if len(empty) == 0 {
+		return randomDirection(directions, rnd), true
+	}
+	return randomDirection(empty, rnd), true
+}
+
+// seekNearest follows the shortest path to the nearest City occupied by other Aliens
+type seekNearest struct{}
+
+// SeekNearest creates MovementStrategy taking the first road of the shortest path to the nearest
+// City occupied by other Aliens. Paths of the same length are preferred in order of City directions.
+func SeekNearest() MovementStrategy {
+	return seekNearest{}
+}
+
+// Choose is the MovementStrategy interface implementation
+func (seekNearest) Choose(alien domain.Alien, city *domain.City, rnd *rand.Rand) (domain.Direction, bool) {
+	// first holds Direction of the first road on the path to every reached City
+	first := map[*domain.City]domain.Direction{city: 0}
+	queue := []*domain.City{city}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, d := range c.Directions() {
+			next := c.OutRoad[d]
+			if _, ok := first[next]; ok {
+				continue
+			}
+			if c == city {
+				first[next] = d
+			} else {
+				first[next] = first[c]
+			}
+			for _, a := range next.Aliens {
+				if a != alien {
+					return first[next], true
+				}
+			}
+			queue = append(queue, next)
+		}
+	}
+	return randomDirection(city.Directions(), rnd), true
+}
+
+// preferUnvisited remembers every City visited by the Alien
+type preferUnvisited struct {
+	visited map[*domain.City]bool
+}
+
+// PreferUnvisited creates MovementStrategy choosing random road to the City the Alien has not visited yet
+func PreferUnvisited() MovementStrategy {
+	return &preferUnvisited{visited: map[*domain.City]bool{}}
+}
+
+// Choose is the MovementStrategy interface implementation
+func (s *preferUnvisited) Choose(_ domain.Alien, city *domain.City, rnd *rand.Rand) (domain.Direction, bool) {
+	s.visited[city] = true
+	directions := city.Directions()
+	fresh := make([]domain.Direction, 0, len(directions))
+	for _, d := range directions {
+		if !s.visited[city.OutRoad[d]] {
+			fresh = append(fresh, d)
+		}
+	}
+	if len(fresh) == 0 {
+		return randomDirection(directions, rnd), true
+	}
+	return randomDirection(fresh, rnd), true
+}
+
+// stayPut skips the move with the given probability
+type stayPut struct {
+	p float64
+}
+
+// StayPut creates MovementStrategy staying in the City with probability p and choosing random road otherwise
+func StayPut(p float64) MovementStrategy {
+	return stayPut{p: p}
+}
+
+// Choose is the MovementStrategy interface implementation
+func (s stayPut) Choose(_ domain.Alien, city *domain.City, rnd *rand.Rand) (domain.Direction, bool) {
+	if rnd.Float64() < s.p {
+		return 0, false
+	}
+	return randomDirection(city.Directions(), rnd), true
+}
+
+// randomDirection picks one of the given Directions with equal probability
+func randomDirection(directions []domain.Direction, rnd *rand.Rand) domain.Direction {
+	return directions[rnd.Intn(len(directions))]
+}
@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"github.com/zippunov/alien-invasion/internal/domain"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestNewStrategy(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{spec: "uniform"},
+		{spec: "Uniform"},
+		{spec: "weighted:north=2,south=0.5"},
+		{spec: "avoid-occupied"},
+		{spec: "seek-nearest"},
+		{spec: "prefer-unvisited"},
+		{spec: "stay-put:0.3"},
+		{spec: "stay-put:1"},
+		{spec: "uniform:1", wantErr: true},
+		{spec: "weighted", wantErr: true},
+		{spec: "weighted:up=2", wantErr: true},
+		{spec: "weighted:north", wantErr: true},
+		{spec: "weighted:north=-1", wantErr: true},
+		{spec: "stay-put", wantErr: true},
+		{spec: "stay-put:2", wantErr: true},
+		{spec: "teleport", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := NewStrategy(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewStrategy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got == nil {
+				t.Errorf("NewStrategy() returned nil strategy")
+			}
+		})
+	}
+}
+
+// strategyMap is the map where every road of the City A leads to the distinct City
+const strategyMap = `A north=B east=C south=D west=E
+B east=F
+C north=B
+D west=E
+E south=D
+F west=B
+`
+
+// chooseMany collects Directions chosen by the strategy for the Alien 0 in the City A
+func chooseMany(s MovementStrategy, m domain.Map, n int) map[domain.Direction]int {
+	rnd := rand.New(rand.NewSource(1))
+	result := map[domain.Direction]int{}
+	for i := 0; i < n; i++ {
+		d, ok := s.Choose(0, m["A"], rnd)
+		if !ok {
+			result[domain.Direction(100)]++
+			continue
+		}
+		result[d]++
+	}
+	return result
+}
+
+func loadStrategyMap(t *testing.T) domain.Map {
+	m := domain.Map{}
+	if err := encoding.UnmarshalTxt(strings.NewReader(strategyMap), m); err != nil {
+		t.Fatalf("UnmarshalTxt() error = %v", err)
+	}
+	m["A"].Aliens = []domain.Alien{0}
+	return m
+}
+
+func TestStrategies_Choose(t *testing.T) {
+	stay := domain.Direction(100)
+	tests := []struct {
+		name     string
+		strategy MovementStrategy
+		occupied []string
+		check    func(t *testing.T, got map[domain.Direction]int)
+	}{
+		{
+			name:     "uniform uses every road",
+			strategy: Uniform(),
+			check: func(t *testing.T, got map[domain.Direction]int) {
+				if len(got) != 4 {
+					t.Errorf("Choose() = %v, want every direction", got)
+				}
+			},
+		},
+		{
+			name:     "weighted skips zero weight roads",
+			strategy: Weighted(map[domain.Direction]float64{domain.North: 0, domain.East: 0, domain.South: 3}),
+			check: func(t *testing.T, got map[domain.Direction]int) {
+				if got[domain.North] != 0 || got[domain.East] != 0 || got[domain.South] <= got[domain.West] {
+					t.Errorf("Choose() = %v, want mostly south, some west", got)
+				}
+			},
+		},
+		{
+			name:     "avoid occupied",
+			strategy: AvoidOccupied(),
+			occupied: []string{"B", "C", "D"},
+			check: func(t *testing.T, got map[domain.Direction]int) {
+				if got[domain.West] != 100 {
+					t.Errorf("Choose() = %v, want west only", got)
+				}
+			},
+		},
+		{
+			name:     "avoid occupied falls back to any road",
+			strategy: AvoidOccupied(),
+			occupied: []string{"B", "C", "D", "E"},
+			check: func(t *testing.T, got map[domain.Direction]int) {
+				if len(got) != 4 {
+					t.Errorf("Choose() = %v, want every direction", got)
+				}
+			},
+		},
+		{
+			name:     "seek nearest",
+			strategy: SeekNearest(),
+			occupied: []string{"F"},
+			check: func(t *testing.T, got map[domain.Direction]int) {
+				if got[domain.North] != 100 {
+					t.Errorf("Choose() = %v, want north only", got)
+				}
+			},
+		},
+		{
+			name:     "stay put",
+			strategy: StayPut(1),
+			check: func(t *testing.T, got map[domain.Direction]int) {
+				if got[stay] != 100 {
+					t.Errorf("Choose() = %v, want to stay", got)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := loadStrategyMap(t)
+			for i, name := range tt.occupied {
+				m[name].Aliens = append(m[name].Aliens, domain.Alien(i+1))
+			}
+			tt.check(t, chooseMany(tt.strategy, m, 100))
+		})
+	}
+}
+
+func TestPreferUnvisited_Choose(t *testing.T) {
+	m := loadStrategyMap(t)
+	s := PreferUnvisited()
+	rnd := rand.New(rand.NewSource(1))
+	seen := map[domain.Direction]bool{}
+	for i := 0; i < 4; i++ {
+		d, _ := s.Choose(0, m["A"], rnd)
+		if seen[d] {
+			t.Fatalf("Choose() = %v twice among first 4 moves", d)
+		}
+		seen[d] = true
+		// visit the City and come back
+		_, _ = s.Choose(0, m["A"].OutRoad[d], rnd)
+	}
+}
@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"io"
+)
+
+// IValidationInfra interface specifies functionality required from the application environment
+// by the Validation usecase.
+type IValidationInfra interface {
+	In() io.Reader
+	InFormat() encoding.Format
+	Symmetric() bool
+	Out() io.Writer
+}
+
+// Validation is the usecase where the Map source is checked and every found problem is reported.
+// In symmetric mode roads conflicting with the reverse roads inferred by the loader are reported as well.
+type Validation struct {
+	in        io.Reader
+	format    encoding.Format
+	symmetric bool
+	out       io.Writer
+}
+
+// InitValidation usecase initialization with provided infrastructure
+func InitValidation(infra IValidationInfra) Validation {
+	return Validation{
+		in:        infra.In(),
+		format:    infra.InFormat(),
+		symmetric: infra.Symmetric(),
+		out:       infra.Out(),
+	}
+}
+
+// Run executes the Usecase. Every problem is written to the output one per line.
+// Returns encoding.Problems as an error if the Map is invalid.
+func (v *Validation) Run() error {
+	src, err := io.ReadAll(v.in)
+	if err != nil {
+		return err
+	}
+	problems, err := encoding.Validate(bytes.NewReader(src), v.format)
+	if err != nil {
+		return err
+	}
+	if v.symmetric && len(problems) == 0 {
+		// the valid source is loaded by the loader of the other usecases, so it reports roads conflicting
+		// with the inferred reverse roads
+		if _, _, err := loadMap(memorySource{src: src, format: v.format, symmetric: v.symmetric}); err != nil {
+			problems = append(problems, encoding.Problem{Message: err.Error()})
+		}
+	}
+	for _, p := range problems {
+		if _, err := fmt.Fprintln(v.out, p.String()); err != nil {
+			return err
+		}
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// memorySource is the Map source read into memory, so it is validated and loaded from the same bytes
+type memorySource struct {
+	src       []byte
+	format    encoding.Format
+	symmetric bool
+}
+
+// In is a part of mapSource interface implementation
+func (s memorySource) In() io.Reader {
+	return bytes.NewReader(s.src)
+}
+
+// InFormat is a part of mapSource interface implementation
+func (s memorySource) InFormat() encoding.Format {
+	return s.format
+}
+
+// Symmetric is a part of mapSource interface implementation
+func (s memorySource) Symmetric() bool {
+	return s.symmetric
+}
@@ -0,0 +1,91 @@
+package usecases
+
+import (
+	"bytes"
+	"errors"
+	"github.com/zippunov/alien-invasion/internal/encoding"
+	"strings"
+	"testing"
+)
+
+func TestValidation_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantOut string
+		wantErr bool
+	}{
+		{
+			name:    "Valid map",
+			in:      testMap,
+			wantOut: "",
+		},
+		{
+			name: "Invalid map",
+			in: `aaa west=bbb top=ccc
+bbb east=aaa east=ccc
+`,
+			wantOut: `1:14: invalid direction name top
+1:18: city ccc is never defined on its own line
+2:14: direction east is taken for city bbb
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			v := Validation{
+				in:     strings.NewReader(tt.in),
+				format: encoding.Txt,
+				out:    out,
+			}
+			err := v.Run()
+			var problems encoding.Problems
+			if (err != nil) != tt.wantErr || (err != nil && !errors.As(err, &problems)) {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("Run() gotOut = %v, want %v", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestValidation_RunSymmetric(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{
+			name: "Half of the edges",
+			in: `aaa west=bbb north=ccc
+bbb north=ddd
+ccc east=ddd
+ddd west=ccc
+`,
+		},
+		{
+			name: "Conflicting roads",
+			in: `aaa west=bbb
+bbb east=ccc
+ccc north=bbb
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := Validation{
+				in:        strings.NewReader(tt.in),
+				format:    encoding.Txt,
+				symmetric: true,
+				out:       &bytes.Buffer{},
+			}
+			if err := v.Run(); (err != nil) != tt.wantErr {
+				t.Errorf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}